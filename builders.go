@@ -1,6 +1,16 @@
 package confetto
 
-import "time"
+import (
+	"fmt"
+	"maps"
+	"math/big"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
 
 // StringBuilder builds a StringParam.
 type StringBuilder struct {
@@ -24,6 +34,13 @@ func (b *StringBuilder) Required() *StringBuilder {
 	return b
 }
 
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *StringBuilder) RequiredInProfile(profiles ...string) *StringBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
 func (b *StringBuilder) Desc(d string) *StringBuilder {
 	b.p.desc = d
 	return b
@@ -34,12 +51,115 @@ func (b *StringBuilder) Secret() *StringBuilder {
 	return b
 }
 
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *StringBuilder) Alias(keys ...string) *StringBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *StringBuilder) DeprecatedAlias(oldKey string) *StringBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *StringBuilder) Short(names ...string) *StringBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *StringBuilder) Inherit(key string) *StringBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *StringBuilder) DenySource(kind SourceKind) *StringBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *StringBuilder) RejectSource(kind SourceKind) *StringBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// NormalizeOneOf requires the value to match one of the allowed values
+// case-insensitively and rewrites it to the canonical spelling from the
+// list, so "PROD" is stored as "prod" if "prod" is allowed.
+func (b *StringBuilder) NormalizeOneOf(allowed ...string) *StringBuilder {
+	b.p.normalizers = append(b.p.normalizers, func(v string) (string, string) {
+		for _, a := range allowed {
+			if strings.EqualFold(v, a) {
+				return a, ""
+			}
+		}
+		return v, ""
+	})
+	b.p.validators = append(b.p.validators, OneOf(allowed...))
+	return b
+}
+
+// Document accepts a YAML mapping or sequence for this parameter and stores
+// it re-serialized as a YAML document. Without it, such values are rejected
+// with a ParseError.
+func (b *StringBuilder) Document() *StringBuilder {
+	b.p.document = true
+	return b
+}
+
+// RelativeToConfig treats the value as a file path and, when it is relative
+// and read from the config file, resolves it against the directory of that
+// file instead of the working directory.
+func (b *StringBuilder) RelativeToConfig() *StringBuilder {
+	b.p.relativeToConfig = true
+	return b
+}
+
+// Forbid rejects the listed values, e.g. a setting known to be unsafe.
+func (b *StringBuilder) Forbid(values ...string) *StringBuilder {
+	b.p.validators = append(b.p.validators, NoneOf(values...))
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *StringBuilder) BindTo(ptr *string) *StringBuilder {
+	b.p.bound = ptr
+	return b
+}
+
 func (b *StringBuilder) Validate(fn func(string) error) *StringBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *StringBuilder) WarnValidate(fn func(string) error) *StringBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
 func (b *StringBuilder) Build() StringParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
 
@@ -65,6 +185,13 @@ func (b *IntBuilder) Required() *IntBuilder {
 	return b
 }
 
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *IntBuilder) RequiredInProfile(profiles ...string) *IntBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
 func (b *IntBuilder) Desc(d string) *IntBuilder {
 	b.p.desc = d
 	return b
@@ -75,12 +202,105 @@ func (b *IntBuilder) Secret() *IntBuilder {
 	return b
 }
 
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *IntBuilder) Alias(keys ...string) *IntBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *IntBuilder) DeprecatedAlias(oldKey string) *IntBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *IntBuilder) Short(names ...string) *IntBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *IntBuilder) Inherit(key string) *IntBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *IntBuilder) DenySource(kind SourceKind) *IntBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *IntBuilder) RejectSource(kind SourceKind) *IntBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Sentinel makes the token (e.g. "unlimited") parse to v. Tokens are
+// matched case-insensitively and several sentinels may be registered.
+func (b *IntBuilder) Sentinel(token string, v int) *IntBuilder {
+	b.p.addSentinel(token, v)
+	return b
+}
+
+// Clamp limits the value to [lo, hi] instead of failing: out-of-range
+// values are replaced by the nearest bound and reported as a warning. A
+// value that is neither set nor defaulted is left alone.
+func (b *IntBuilder) Clamp(lo, hi int) *IntBuilder {
+	b.p.normalizers = append(b.p.normalizers, clamp(lo, hi))
+	return b
+}
+
+// Forbid rejects the listed values, e.g. a setting known to be unsafe.
+func (b *IntBuilder) Forbid(values ...int) *IntBuilder {
+	b.p.validators = append(b.p.validators, NoneOf(values...))
+	return b
+}
+
+// Unit labels the value with its unit, e.g. "seconds" or "requests/s", for
+// operators reading usage and dumps. It does not affect parsing.
+func (b *IntBuilder) Unit(s string) *IntBuilder {
+	b.p.unitLabel = s
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *IntBuilder) BindTo(ptr *int) *IntBuilder {
+	b.p.bound = ptr
+	return b
+}
+
 func (b *IntBuilder) Validate(fn func(int) error) *IntBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *IntBuilder) WarnValidate(fn func(int) error) *IntBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
 func (b *IntBuilder) Build() IntParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
 
@@ -106,6 +326,13 @@ func (b *BoolBuilder) Required() *BoolBuilder {
 	return b
 }
 
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *BoolBuilder) RequiredInProfile(profiles ...string) *BoolBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
 func (b *BoolBuilder) Desc(d string) *BoolBuilder {
 	b.p.desc = d
 	return b
@@ -116,12 +343,93 @@ func (b *BoolBuilder) Secret() *BoolBuilder {
 	return b
 }
 
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *BoolBuilder) Alias(keys ...string) *BoolBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *BoolBuilder) DeprecatedAlias(oldKey string) *BoolBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *BoolBuilder) Short(names ...string) *BoolBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *BoolBuilder) Inherit(key string) *BoolBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *BoolBuilder) DenySource(kind SourceKind) *BoolBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *BoolBuilder) RejectSource(kind SourceKind) *BoolBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Presence makes the parameter true when its key is merely listed in the
+// config file, either with no value ("beta:") or as an item of a sequence
+// at the parent key ("features: [beta]"). An explicit value in the file,
+// or a value from a higher-priority source, still takes precedence.
+func (b *BoolBuilder) Presence() *BoolBuilder {
+	b.p.presence = true
+	return b
+}
+
+// Lenient also accepts yes/no, y/n and on/off, in any case, when parsing
+// the value from a string.
+func (b *BoolBuilder) Lenient() *BoolBuilder {
+	b.p.lenient = true
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *BoolBuilder) BindTo(ptr *bool) *BoolBuilder {
+	b.p.bound = ptr
+	return b
+}
+
 func (b *BoolBuilder) Validate(fn func(bool) error) *BoolBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *BoolBuilder) WarnValidate(fn func(bool) error) *BoolBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
 func (b *BoolBuilder) Build() BoolParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
 
@@ -136,6 +444,7 @@ func Float() *FloatBuilder {
 }
 
 func (b *FloatBuilder) Default(v float64) *FloatBuilder {
+	v = b.p.scaled(v)
 	b.p.defaultVal = v
 	b.p.value = v
 	b.p.hasDefVal = true
@@ -147,6 +456,13 @@ func (b *FloatBuilder) Required() *FloatBuilder {
 	return b
 }
 
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *FloatBuilder) RequiredInProfile(profiles ...string) *FloatBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
 func (b *FloatBuilder) Desc(d string) *FloatBuilder {
 	b.p.desc = d
 	return b
@@ -157,12 +473,127 @@ func (b *FloatBuilder) Secret() *FloatBuilder {
 	return b
 }
 
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *FloatBuilder) Alias(keys ...string) *FloatBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *FloatBuilder) DeprecatedAlias(oldKey string) *FloatBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *FloatBuilder) Short(names ...string) *FloatBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *FloatBuilder) Inherit(key string) *FloatBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *FloatBuilder) DenySource(kind SourceKind) *FloatBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *FloatBuilder) RejectSource(kind SourceKind) *FloatBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Sentinel makes the token (e.g. "unlimited") parse to v. Tokens are
+// matched case-insensitively and several sentinels may be registered.
+func (b *FloatBuilder) Sentinel(token string, v float64) *FloatBuilder {
+	b.p.addSentinel(token, v)
+	return b
+}
+
+// Clamp limits the value to [lo, hi] instead of failing: out-of-range
+// values are replaced by the nearest bound and reported as a warning. A
+// value that is neither set nor defaulted is left alone.
+func (b *FloatBuilder) Clamp(lo, hi float64) *FloatBuilder {
+	b.p.normalizers = append(b.p.normalizers, clamp(lo, hi))
+	return b
+}
+
+// Percentage reads the value, including the default, as a percentage in
+// [0, 100], optionally followed by "%", and stores it as a fraction in
+// [0, 1]: "25" and "25%" both load as 0.25. Values outside the range fail
+// validation. Only Default takes a percentage; Sentinel, Clamp, Forbid and
+// validators work on the stored fraction, so Clamp(0, 0.5) caps it at 50%.
+func (b *FloatBuilder) Percentage() *FloatBuilder {
+	if !b.p.percent {
+		b.p.percent = true
+		b.p.defaultVal = b.p.scaled(b.p.defaultVal)
+		b.p.value = b.p.defaultVal
+		b.p.validators = append(b.p.validators, func(v float64) error {
+			if v < 0 || v > 1 {
+				return fmt.Errorf(
+					"%w: value %g%% is not in range [0%%, 100%%]", ErrValidation, v*100,
+				)
+			}
+			return nil
+		})
+	}
+	return b
+}
+
+// Forbid rejects the listed values, e.g. a setting known to be unsafe.
+func (b *FloatBuilder) Forbid(values ...float64) *FloatBuilder {
+	b.p.validators = append(b.p.validators, NoneOf(values...))
+	return b
+}
+
+// Unit labels the value with its unit, e.g. "seconds" or "requests/s", for
+// operators reading usage and dumps. It does not affect parsing.
+func (b *FloatBuilder) Unit(s string) *FloatBuilder {
+	b.p.unitLabel = s
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *FloatBuilder) BindTo(ptr *float64) *FloatBuilder {
+	b.p.bound = ptr
+	return b
+}
+
 func (b *FloatBuilder) Validate(fn func(float64) error) *FloatBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *FloatBuilder) WarnValidate(fn func(float64) error) *FloatBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
 func (b *FloatBuilder) Build() FloatParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
 
@@ -188,6 +619,13 @@ func (b *DurationBuilder) Required() *DurationBuilder {
 	return b
 }
 
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *DurationBuilder) RequiredInProfile(profiles ...string) *DurationBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
 func (b *DurationBuilder) Desc(d string) *DurationBuilder {
 	b.p.desc = d
 	return b
@@ -198,177 +636,894 @@ func (b *DurationBuilder) Secret() *DurationBuilder {
 	return b
 }
 
-func (b *DurationBuilder) Validate(fn func(time.Duration) error) *DurationBuilder {
-	b.p.validators = append(b.p.validators, fn)
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *DurationBuilder) Alias(keys ...string) *DurationBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
 	return b
 }
 
-func (b *DurationBuilder) Build() DurationParam {
-	return b.p
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *DurationBuilder) DeprecatedAlias(oldKey string) *DurationBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
 }
 
-// StringListBuilder builds a StringListParam.
-type StringListBuilder struct {
-	p StringListParam
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *DurationBuilder) Short(names ...string) *DurationBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
 }
 
-// StringList returns a new StringListBuilder.
-func StringList() *StringListBuilder {
-	return &StringListBuilder{}
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *DurationBuilder) Inherit(key string) *DurationBuilder {
+	b.p.parentKey = key
+	return b
 }
 
-func (b *StringListBuilder) Default(v []string) *StringListBuilder {
-	b.p.defaultVal = v
-	b.p.value = v
-	b.p.hasDefVal = true
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *DurationBuilder) DenySource(kind SourceKind) *DurationBuilder {
+	b.p.denySource(kind, false)
 	return b
 }
 
-func (b *StringListBuilder) Required() *StringListBuilder {
-	b.p.required = true
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *DurationBuilder) RejectSource(kind SourceKind) *DurationBuilder {
+	b.p.denySource(kind, true)
 	return b
 }
 
-func (b *StringListBuilder) Desc(d string) *StringListBuilder {
-	b.p.desc = d
+// Sentinel makes the token (e.g. "unlimited") parse to v. Tokens are
+// matched case-insensitively and several sentinels may be registered.
+func (b *DurationBuilder) Sentinel(token string, v time.Duration) *DurationBuilder {
+	b.p.addSentinel(token, v)
 	return b
 }
 
-func (b *StringListBuilder) Secret() *StringListBuilder {
-	b.p.secret = true
+// Clamp limits the value to [lo, hi] instead of failing: out-of-range
+// values are replaced by the nearest bound and reported as a warning. A
+// value that is neither set nor defaulted is left alone.
+func (b *DurationBuilder) Clamp(lo, hi time.Duration) *DurationBuilder {
+	b.p.normalizers = append(b.p.normalizers, clamp(lo, hi))
 	return b
 }
 
-func (b *StringListBuilder) Validate(fn func([]string) error) *StringListBuilder {
+// Unit sets the unit of numbers given without one, so that "30" means 30
+// units; the default is time.Second. Values with a unit, such as "5s", are
+// not affected, and neither are numbers in YAML, which are nanoseconds.
+func (b *DurationBuilder) Unit(d time.Duration) *DurationBuilder {
+	b.p.bareUnit = d
+	return b
+}
+
+// WithinDeadline requires the value not to exceed the time left before the
+// deadline of the context passed to LoadContext. It has no effect without a
+// deadline.
+func (b *DurationBuilder) WithinDeadline() *DurationBuilder {
+	b.p.withinDeadline = true
+	return b
+}
+
+// Forbid rejects the listed values, e.g. a setting known to be unsafe.
+func (b *DurationBuilder) Forbid(values ...time.Duration) *DurationBuilder {
+	b.p.validators = append(b.p.validators, NoneOf(values...))
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *DurationBuilder) BindTo(ptr *time.Duration) *DurationBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *DurationBuilder) Validate(fn func(time.Duration) error) *DurationBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
-func (b *StringListBuilder) Build() StringListParam {
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *DurationBuilder) WarnValidate(fn func(time.Duration) error) *DurationBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *DurationBuilder) Build() DurationParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
 
-// IntListBuilder builds an IntListParam.
-type IntListBuilder struct {
-	p IntListParam
+// ByteSizeBuilder builds a ByteSizeParam.
+type ByteSizeBuilder struct {
+	p ByteSizeParam
 }
 
-// IntList returns a new IntListBuilder.
-func IntList() *IntListBuilder {
-	return &IntListBuilder{}
+// ByteSize returns a new ByteSizeBuilder.
+func ByteSize() *ByteSizeBuilder {
+	return &ByteSizeBuilder{}
 }
 
-func (b *IntListBuilder) Default(v []int) *IntListBuilder {
+func (b *ByteSizeBuilder) Default(v int64) *ByteSizeBuilder {
 	b.p.defaultVal = v
 	b.p.value = v
 	b.p.hasDefVal = true
 	return b
 }
 
-func (b *IntListBuilder) Required() *IntListBuilder {
+func (b *ByteSizeBuilder) Required() *ByteSizeBuilder {
 	b.p.required = true
 	return b
 }
 
-func (b *IntListBuilder) Desc(d string) *IntListBuilder {
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *ByteSizeBuilder) RequiredInProfile(profiles ...string) *ByteSizeBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *ByteSizeBuilder) Desc(d string) *ByteSizeBuilder {
 	b.p.desc = d
 	return b
 }
 
-func (b *IntListBuilder) Secret() *IntListBuilder {
+func (b *ByteSizeBuilder) Secret() *ByteSizeBuilder {
 	b.p.secret = true
 	return b
 }
 
-func (b *IntListBuilder) Validate(fn func([]int) error) *IntListBuilder {
-	b.p.validators = append(b.p.validators, fn)
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *ByteSizeBuilder) Alias(keys ...string) *ByteSizeBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
 	return b
 }
 
-func (b *IntListBuilder) Build() IntListParam {
-	return b.p
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *ByteSizeBuilder) DeprecatedAlias(oldKey string) *ByteSizeBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
 }
 
-// BoolListBuilder builds a BoolListParam.
-type BoolListBuilder struct {
-	p BoolListParam
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *ByteSizeBuilder) Short(names ...string) *ByteSizeBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
 }
 
-// BoolList returns a new BoolListBuilder.
-func BoolList() *BoolListBuilder {
-	return &BoolListBuilder{}
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *ByteSizeBuilder) Inherit(key string) *ByteSizeBuilder {
+	b.p.parentKey = key
+	return b
 }
 
-func (b *BoolListBuilder) Default(v []bool) *BoolListBuilder {
-	b.p.defaultVal = v
-	b.p.value = v
-	b.p.hasDefVal = true
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *ByteSizeBuilder) DenySource(kind SourceKind) *ByteSizeBuilder {
+	b.p.denySource(kind, false)
 	return b
 }
 
-func (b *BoolListBuilder) Required() *BoolListBuilder {
-	b.p.required = true
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *ByteSizeBuilder) RejectSource(kind SourceKind) *ByteSizeBuilder {
+	b.p.denySource(kind, true)
 	return b
 }
 
-func (b *BoolListBuilder) Desc(d string) *BoolListBuilder {
-	b.p.desc = d
+// Sentinel makes the token (e.g. "unlimited") parse to v. Tokens are
+// matched case-insensitively and several sentinels may be registered.
+func (b *ByteSizeBuilder) Sentinel(token string, v int64) *ByteSizeBuilder {
+	b.p.addSentinel(token, v)
 	return b
 }
 
-func (b *BoolListBuilder) Secret() *BoolListBuilder {
-	b.p.secret = true
+// Clamp limits the value to [lo, hi] instead of failing: out-of-range
+// values are replaced by the nearest bound and reported as a warning. A
+// value that is neither set nor defaulted is left alone.
+func (b *ByteSizeBuilder) Clamp(lo, hi int64) *ByteSizeBuilder {
+	b.p.normalizers = append(b.p.normalizers, clamp(lo, hi))
 	return b
 }
 
-func (b *BoolListBuilder) Validate(fn func([]bool) error) *BoolListBuilder {
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *ByteSizeBuilder) BindTo(ptr *int64) *ByteSizeBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *ByteSizeBuilder) Validate(fn func(int64) error) *ByteSizeBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
-func (b *BoolListBuilder) Build() BoolListParam {
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *ByteSizeBuilder) WarnValidate(fn func(int64) error) *ByteSizeBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *ByteSizeBuilder) Build() ByteSizeParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
 
-// FloatListBuilder builds a FloatListParam.
-type FloatListBuilder struct {
-	p FloatListParam
+// TimeBuilder builds a TimeParam.
+type TimeBuilder struct {
+	p TimeParam
 }
 
-// FloatList returns a new FloatListBuilder.
-func FloatList() *FloatListBuilder {
-	return &FloatListBuilder{}
+// Time returns a new TimeBuilder.
+func Time() *TimeBuilder {
+	return &TimeBuilder{}
 }
 
-func (b *FloatListBuilder) Default(v []float64) *FloatListBuilder {
+func (b *TimeBuilder) Default(v time.Time) *TimeBuilder {
 	b.p.defaultVal = v
 	b.p.value = v
 	b.p.hasDefVal = true
 	return b
 }
 
-func (b *FloatListBuilder) Required() *FloatListBuilder {
+func (b *TimeBuilder) Required() *TimeBuilder {
 	b.p.required = true
 	return b
 }
 
-func (b *FloatListBuilder) Desc(d string) *FloatListBuilder {
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *TimeBuilder) RequiredInProfile(profiles ...string) *TimeBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *TimeBuilder) Desc(d string) *TimeBuilder {
 	b.p.desc = d
 	return b
 }
 
-func (b *FloatListBuilder) Secret() *FloatListBuilder {
+func (b *TimeBuilder) Secret() *TimeBuilder {
 	b.p.secret = true
 	return b
 }
 
-func (b *FloatListBuilder) Validate(fn func([]float64) error) *FloatListBuilder {
-	b.p.validators = append(b.p.validators, fn)
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *TimeBuilder) Alias(keys ...string) *TimeBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
 	return b
 }
 
-func (b *FloatListBuilder) Build() FloatListParam {
-	return b.p
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *TimeBuilder) DeprecatedAlias(oldKey string) *TimeBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *TimeBuilder) Short(names ...string) *TimeBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *TimeBuilder) Inherit(key string) *TimeBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *TimeBuilder) DenySource(kind SourceKind) *TimeBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *TimeBuilder) RejectSource(kind SourceKind) *TimeBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Layout sets the time layout used to parse and format the value, as in
+// time.Parse. The default is time.RFC3339.
+func (b *TimeBuilder) Layout(layout string) *TimeBuilder {
+	b.p.layout = layout
+	return b
+}
+
+// Future requires the value to be after the time of the Load, e.g. for a
+// token expiry. The time is read from Options.Now.
+func (b *TimeBuilder) Future() *TimeBuilder {
+	b.p.future = true
+	return b
+}
+
+// Past requires the value to be before the time of the Load, e.g. for an
+// activation date. The time is read from Options.Now.
+func (b *TimeBuilder) Past() *TimeBuilder {
+	b.p.past = true
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *TimeBuilder) BindTo(ptr *time.Time) *TimeBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *TimeBuilder) Validate(fn func(time.Time) error) *TimeBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *TimeBuilder) WarnValidate(fn func(time.Time) error) *TimeBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *TimeBuilder) Build() TimeParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// StringListBuilder builds a StringListParam.
+type StringListBuilder struct {
+	p StringListParam
+}
+
+// StringList returns a new StringListBuilder.
+func StringList() *StringListBuilder {
+	b := &StringListBuilder{}
+	b.p.clone = slices.Clone[[]string]
+	return b
+}
+
+func (b *StringListBuilder) Default(v []string) *StringListBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *StringListBuilder) Required() *StringListBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *StringListBuilder) RequiredInProfile(profiles ...string) *StringListBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *StringListBuilder) Desc(d string) *StringListBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *StringListBuilder) Secret() *StringListBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *StringListBuilder) Alias(keys ...string) *StringListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *StringListBuilder) DeprecatedAlias(oldKey string) *StringListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *StringListBuilder) Short(names ...string) *StringListBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *StringListBuilder) Inherit(key string) *StringListBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *StringListBuilder) DenySource(kind SourceKind) *StringListBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *StringListBuilder) RejectSource(kind SourceKind) *StringListBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *StringListBuilder) BindTo(ptr *[]string) *StringListBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *StringListBuilder) Validate(fn func([]string) error) *StringListBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *StringListBuilder) WarnValidate(fn func([]string) error) *StringListBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+// MinItemsIfSet requires at least n items when the value is set, by a
+// source or with Set, so an optional list may be left unset, even with a
+// shorter default, but needs n items once provided. A list set to empty
+// fails.
+func (b *StringListBuilder) MinItemsIfSet(n int) *StringListBuilder {
+	b.p.setValidators = append(b.p.setValidators, MinItems[string](n))
+	return b
+}
+
+func (b *StringListBuilder) Build() StringListParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// IntListBuilder builds an IntListParam.
+type IntListBuilder struct {
+	p IntListParam
+}
+
+// IntList returns a new IntListBuilder.
+func IntList() *IntListBuilder {
+	b := &IntListBuilder{}
+	b.p.clone = slices.Clone[[]int]
+	return b
+}
+
+func (b *IntListBuilder) Default(v []int) *IntListBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *IntListBuilder) Required() *IntListBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *IntListBuilder) RequiredInProfile(profiles ...string) *IntListBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *IntListBuilder) Desc(d string) *IntListBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *IntListBuilder) Secret() *IntListBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *IntListBuilder) Alias(keys ...string) *IntListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *IntListBuilder) DeprecatedAlias(oldKey string) *IntListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *IntListBuilder) Short(names ...string) *IntListBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *IntListBuilder) Inherit(key string) *IntListBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *IntListBuilder) DenySource(kind SourceKind) *IntListBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *IntListBuilder) RejectSource(kind SourceKind) *IntListBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Unit labels the value with its unit, e.g. "seconds" or "requests/s", for
+// operators reading usage and dumps. It does not affect parsing.
+func (b *IntListBuilder) Unit(s string) *IntListBuilder {
+	b.p.unitLabel = s
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *IntListBuilder) BindTo(ptr *[]int) *IntListBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *IntListBuilder) Validate(fn func([]int) error) *IntListBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *IntListBuilder) WarnValidate(fn func([]int) error) *IntListBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+// MinItemsIfSet requires at least n items when the value is set, by a
+// source or with Set, so an optional list may be left unset, even with a
+// shorter default, but needs n items once provided. A list set to empty
+// fails.
+func (b *IntListBuilder) MinItemsIfSet(n int) *IntListBuilder {
+	b.p.setValidators = append(b.p.setValidators, MinItems[int](n))
+	return b
+}
+
+func (b *IntListBuilder) Build() IntListParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// BoolListBuilder builds a BoolListParam.
+type BoolListBuilder struct {
+	p BoolListParam
+}
+
+// BoolList returns a new BoolListBuilder.
+func BoolList() *BoolListBuilder {
+	b := &BoolListBuilder{}
+	b.p.clone = slices.Clone[[]bool]
+	return b
+}
+
+func (b *BoolListBuilder) Default(v []bool) *BoolListBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *BoolListBuilder) Required() *BoolListBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *BoolListBuilder) RequiredInProfile(profiles ...string) *BoolListBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *BoolListBuilder) Desc(d string) *BoolListBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *BoolListBuilder) Secret() *BoolListBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *BoolListBuilder) Alias(keys ...string) *BoolListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *BoolListBuilder) DeprecatedAlias(oldKey string) *BoolListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *BoolListBuilder) Short(names ...string) *BoolListBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *BoolListBuilder) Inherit(key string) *BoolListBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *BoolListBuilder) DenySource(kind SourceKind) *BoolListBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *BoolListBuilder) RejectSource(kind SourceKind) *BoolListBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Lenient also accepts yes/no, y/n and on/off, in any case, for each item
+// parsed from a string.
+func (b *BoolListBuilder) Lenient() *BoolListBuilder {
+	b.p.lenient = true
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *BoolListBuilder) BindTo(ptr *[]bool) *BoolListBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *BoolListBuilder) Validate(fn func([]bool) error) *BoolListBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *BoolListBuilder) WarnValidate(fn func([]bool) error) *BoolListBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+// MinItemsIfSet requires at least n items when the value is set, by a
+// source or with Set, so an optional list may be left unset, even with a
+// shorter default, but needs n items once provided. A list set to empty
+// fails.
+func (b *BoolListBuilder) MinItemsIfSet(n int) *BoolListBuilder {
+	b.p.setValidators = append(b.p.setValidators, MinItems[bool](n))
+	return b
+}
+
+func (b *BoolListBuilder) Build() BoolListParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// FloatListBuilder builds a FloatListParam.
+type FloatListBuilder struct {
+	p FloatListParam
+}
+
+// FloatList returns a new FloatListBuilder.
+func FloatList() *FloatListBuilder {
+	b := &FloatListBuilder{}
+	b.p.clone = slices.Clone[[]float64]
+	return b
+}
+
+func (b *FloatListBuilder) Default(v []float64) *FloatListBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *FloatListBuilder) Required() *FloatListBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *FloatListBuilder) RequiredInProfile(profiles ...string) *FloatListBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *FloatListBuilder) Desc(d string) *FloatListBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *FloatListBuilder) Secret() *FloatListBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *FloatListBuilder) Alias(keys ...string) *FloatListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *FloatListBuilder) DeprecatedAlias(oldKey string) *FloatListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *FloatListBuilder) Short(names ...string) *FloatListBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *FloatListBuilder) Inherit(key string) *FloatListBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *FloatListBuilder) DenySource(kind SourceKind) *FloatListBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *FloatListBuilder) RejectSource(kind SourceKind) *FloatListBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Unit labels the value with its unit, e.g. "seconds" or "requests/s", for
+// operators reading usage and dumps. It does not affect parsing.
+func (b *FloatListBuilder) Unit(s string) *FloatListBuilder {
+	b.p.unitLabel = s
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *FloatListBuilder) BindTo(ptr *[]float64) *FloatListBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *FloatListBuilder) Validate(fn func([]float64) error) *FloatListBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *FloatListBuilder) WarnValidate(fn func([]float64) error) *FloatListBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+// MinItemsIfSet requires at least n items when the value is set, by a
+// source or with Set, so an optional list may be left unset, even with a
+// shorter default, but needs n items once provided. A list set to empty
+// fails.
+func (b *FloatListBuilder) MinItemsIfSet(n int) *FloatListBuilder {
+	b.p.setValidators = append(b.p.setValidators, MinItems[float64](n))
+	return b
+}
+
+func (b *FloatListBuilder) Build() FloatListParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
 }
 
 // DurationListBuilder builds a DurationListParam.
@@ -376,38 +1531,847 @@ type DurationListBuilder struct {
 	p DurationListParam
 }
 
-// DurationList returns a new DurationListBuilder.
-func DurationList() *DurationListBuilder {
-	return &DurationListBuilder{}
+// DurationList returns a new DurationListBuilder.
+func DurationList() *DurationListBuilder {
+	b := &DurationListBuilder{}
+	b.p.clone = slices.Clone[[]time.Duration]
+	return b
+}
+
+func (b *DurationListBuilder) Default(v []time.Duration) *DurationListBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *DurationListBuilder) Required() *DurationListBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *DurationListBuilder) RequiredInProfile(profiles ...string) *DurationListBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *DurationListBuilder) Desc(d string) *DurationListBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *DurationListBuilder) Secret() *DurationListBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *DurationListBuilder) Alias(keys ...string) *DurationListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *DurationListBuilder) DeprecatedAlias(oldKey string) *DurationListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *DurationListBuilder) Short(names ...string) *DurationListBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *DurationListBuilder) Inherit(key string) *DurationListBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *DurationListBuilder) DenySource(kind SourceKind) *DurationListBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *DurationListBuilder) RejectSource(kind SourceKind) *DurationListBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *DurationListBuilder) BindTo(ptr *[]time.Duration) *DurationListBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *DurationListBuilder) Validate(fn func([]time.Duration) error) *DurationListBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *DurationListBuilder) WarnValidate(fn func([]time.Duration) error) *DurationListBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+// MinItemsIfSet requires at least n items when the value is set, by a
+// source or with Set, so an optional list may be left unset, even with a
+// shorter default, but needs n items once provided. A list set to empty
+// fails.
+func (b *DurationListBuilder) MinItemsIfSet(n int) *DurationListBuilder {
+	b.p.setValidators = append(b.p.setValidators, MinItems[time.Duration](n))
+	return b
+}
+
+func (b *DurationListBuilder) Build() DurationListParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// DecimalBuilder builds a DecimalParam.
+type DecimalBuilder struct {
+	p DecimalParam
+}
+
+// Decimal returns a new DecimalBuilder.
+func Decimal() *DecimalBuilder {
+	b := &DecimalBuilder{}
+	b.p.clone = cloneRat
+	return b
+}
+
+func (b *DecimalBuilder) Default(v *big.Rat) *DecimalBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *DecimalBuilder) Required() *DecimalBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *DecimalBuilder) RequiredInProfile(profiles ...string) *DecimalBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *DecimalBuilder) Desc(d string) *DecimalBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *DecimalBuilder) Secret() *DecimalBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *DecimalBuilder) Alias(keys ...string) *DecimalBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *DecimalBuilder) DeprecatedAlias(oldKey string) *DecimalBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *DecimalBuilder) Short(names ...string) *DecimalBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *DecimalBuilder) Inherit(key string) *DecimalBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *DecimalBuilder) DenySource(kind SourceKind) *DecimalBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *DecimalBuilder) RejectSource(kind SourceKind) *DecimalBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Scale rejects values with more than n fractional digits and formats the
+// value with exactly n fractional digits.
+func (b *DecimalBuilder) Scale(n int) *DecimalBuilder {
+	b.p.scale = n
+	b.p.scaled = true
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *DecimalBuilder) BindTo(ptr **big.Rat) *DecimalBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *DecimalBuilder) Validate(fn func(*big.Rat) error) *DecimalBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *DecimalBuilder) WarnValidate(fn func(*big.Rat) error) *DecimalBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *DecimalBuilder) Build() DecimalParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// NestedStringListBuilder builds a NestedStringListParam.
+type NestedStringListBuilder struct {
+	p NestedStringListParam
+}
+
+// NestedStringList returns a new NestedStringListBuilder.
+func NestedStringList() *NestedStringListBuilder {
+	b := &NestedStringListBuilder{}
+	b.p.clone = cloneNested
+	return b
+}
+
+func (b *NestedStringListBuilder) Default(v [][]string) *NestedStringListBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *NestedStringListBuilder) Required() *NestedStringListBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *NestedStringListBuilder) RequiredInProfile(profiles ...string) *NestedStringListBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *NestedStringListBuilder) Desc(d string) *NestedStringListBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *NestedStringListBuilder) Secret() *NestedStringListBuilder {
+	b.p.secret = true
+	return b
 }
 
-func (b *DurationListBuilder) Default(v []time.Duration) *DurationListBuilder {
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *NestedStringListBuilder) Alias(keys ...string) *NestedStringListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *NestedStringListBuilder) DeprecatedAlias(oldKey string) *NestedStringListBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *NestedStringListBuilder) Short(names ...string) *NestedStringListBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *NestedStringListBuilder) Inherit(key string) *NestedStringListBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *NestedStringListBuilder) DenySource(kind SourceKind) *NestedStringListBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *NestedStringListBuilder) RejectSource(kind SourceKind) *NestedStringListBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// InnerSeparator sets the separator that splits each list item into its
+// fields (default ":").
+func (b *NestedStringListBuilder) InnerSeparator(sep string) *NestedStringListBuilder {
+	b.p.innerSep = sep
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *NestedStringListBuilder) BindTo(ptr *[][]string) *NestedStringListBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *NestedStringListBuilder) Validate(fn func([][]string) error) *NestedStringListBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *NestedStringListBuilder) WarnValidate(fn func([][]string) error) *NestedStringListBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+// MinItemsIfSet requires at least n items when the value is set, by a
+// source or with Set, so an optional list may be left unset, even with a
+// shorter default, but needs n items once provided. A list set to empty
+// fails.
+func (b *NestedStringListBuilder) MinItemsIfSet(n int) *NestedStringListBuilder {
+	b.p.setValidators = append(b.p.setValidators, MinItems[[]string](n))
+	return b
+}
+
+func (b *NestedStringListBuilder) Build() NestedStringListParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// MapStringBuilder builds a MapStringParam.
+type MapStringBuilder struct {
+	p MapStringParam
+}
+
+// MapString returns a new MapStringBuilder.
+func MapString() *MapStringBuilder {
+	b := &MapStringBuilder{}
+	b.p.clone = maps.Clone[map[string]string]
+	return b
+}
+
+func (b *MapStringBuilder) Default(v map[string]string) *MapStringBuilder {
 	b.p.defaultVal = v
 	b.p.value = v
 	b.p.hasDefVal = true
 	return b
 }
 
-func (b *DurationListBuilder) Required() *DurationListBuilder {
+func (b *MapStringBuilder) Required() *MapStringBuilder {
 	b.p.required = true
 	return b
 }
 
-func (b *DurationListBuilder) Desc(d string) *DurationListBuilder {
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *MapStringBuilder) RequiredInProfile(profiles ...string) *MapStringBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *MapStringBuilder) Desc(d string) *MapStringBuilder {
 	b.p.desc = d
 	return b
 }
 
-func (b *DurationListBuilder) Secret() *DurationListBuilder {
+func (b *MapStringBuilder) Secret() *MapStringBuilder {
 	b.p.secret = true
 	return b
 }
 
-func (b *DurationListBuilder) Validate(fn func([]time.Duration) error) *DurationListBuilder {
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *MapStringBuilder) Alias(keys ...string) *MapStringBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *MapStringBuilder) DeprecatedAlias(oldKey string) *MapStringBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *MapStringBuilder) Short(names ...string) *MapStringBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *MapStringBuilder) Inherit(key string) *MapStringBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *MapStringBuilder) DenySource(kind SourceKind) *MapStringBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *MapStringBuilder) RejectSource(kind SourceKind) *MapStringBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *MapStringBuilder) BindTo(ptr *map[string]string) *MapStringBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *MapStringBuilder) Validate(fn func(map[string]string) error) *MapStringBuilder {
 	b.p.validators = append(b.p.validators, fn)
 	return b
 }
 
-func (b *DurationListBuilder) Build() DurationListParam {
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *MapStringBuilder) WarnValidate(fn func(map[string]string) error) *MapStringBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *MapStringBuilder) Build() MapStringParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// IPBuilder builds an IPParam.
+type IPBuilder struct {
+	p IPParam
+}
+
+// IP returns a new IPBuilder.
+func IP() *IPBuilder {
+	b := &IPBuilder{}
+	b.p.clone = slices.Clone[net.IP]
+	return b
+}
+
+func (b *IPBuilder) Default(v net.IP) *IPBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *IPBuilder) Required() *IPBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *IPBuilder) RequiredInProfile(profiles ...string) *IPBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *IPBuilder) Desc(d string) *IPBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *IPBuilder) Secret() *IPBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *IPBuilder) Alias(keys ...string) *IPBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *IPBuilder) DeprecatedAlias(oldKey string) *IPBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *IPBuilder) Short(names ...string) *IPBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *IPBuilder) Inherit(key string) *IPBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *IPBuilder) DenySource(kind SourceKind) *IPBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *IPBuilder) RejectSource(kind SourceKind) *IPBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *IPBuilder) BindTo(ptr *net.IP) *IPBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *IPBuilder) Validate(fn func(net.IP) error) *IPBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *IPBuilder) WarnValidate(fn func(net.IP) error) *IPBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *IPBuilder) Build() IPParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// IPNetBuilder builds an IPNetParam.
+type IPNetBuilder struct {
+	p IPNetParam
+}
+
+// IPNet returns a new IPNetBuilder.
+func IPNet() *IPNetBuilder {
+	b := &IPNetBuilder{}
+	b.p.clone = cloneIPNet
+	return b
+}
+
+func (b *IPNetBuilder) Default(v *net.IPNet) *IPNetBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *IPNetBuilder) Required() *IPNetBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *IPNetBuilder) RequiredInProfile(profiles ...string) *IPNetBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *IPNetBuilder) Desc(d string) *IPNetBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *IPNetBuilder) Secret() *IPNetBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *IPNetBuilder) Alias(keys ...string) *IPNetBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *IPNetBuilder) DeprecatedAlias(oldKey string) *IPNetBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *IPNetBuilder) Short(names ...string) *IPNetBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *IPNetBuilder) Inherit(key string) *IPNetBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *IPNetBuilder) DenySource(kind SourceKind) *IPNetBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *IPNetBuilder) RejectSource(kind SourceKind) *IPNetBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *IPNetBuilder) BindTo(ptr **net.IPNet) *IPNetBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *IPNetBuilder) Validate(fn func(*net.IPNet) error) *IPNetBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *IPNetBuilder) WarnValidate(fn func(*net.IPNet) error) *IPNetBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *IPNetBuilder) Build() IPNetParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
+	return b.p
+}
+
+// URLBuilder builds a URLParam.
+type URLBuilder struct {
+	p URLParam
+}
+
+// URL returns a new URLBuilder.
+func URL() *URLBuilder {
+	b := &URLBuilder{}
+	b.p.clone = cloneURL
+	return b
+}
+
+func (b *URLBuilder) Default(v *url.URL) *URLBuilder {
+	b.p.defaultVal = v
+	b.p.value = v
+	b.p.hasDefVal = true
+	return b
+}
+
+func (b *URLBuilder) Required() *URLBuilder {
+	b.p.required = true
+	return b
+}
+
+// RequiredInProfile makes the parameter required only when Options.Profile
+// is one of the given profiles, e.g. "prod".
+func (b *URLBuilder) RequiredInProfile(profiles ...string) *URLBuilder {
+	b.p.profiles = append(b.p.profiles, profiles...)
+	return b
+}
+
+func (b *URLBuilder) Desc(d string) *URLBuilder {
+	b.p.desc = d
+	return b
+}
+
+func (b *URLBuilder) Secret() *URLBuilder {
+	b.p.secret = true
+	return b
+}
+
+// Alias adds alternative full keys that are tried, in order, after the
+// canonical key in each source.
+func (b *URLBuilder) Alias(keys ...string) *URLBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, keys...)
+	return b
+}
+
+// DeprecatedAlias adds a former full key of the parameter, tried like an
+// alias after the canonical key in each source. Using it loads the value
+// but adds a warning, reported by Loader.Warnings, naming the new key.
+func (b *URLBuilder) DeprecatedAlias(oldKey string) *URLBuilder {
+	b.p.aliasKeys = append(b.p.aliasKeys, oldKey)
+	b.p.deprecatedKeys = append(b.p.deprecatedKeys, oldKey)
+	return b
+}
+
+// Short adds short command line flags, such as "v" for -v, that set the
+// parameter like its --key flag.
+func (b *URLBuilder) Short(names ...string) *URLBuilder {
+	for _, n := range names {
+		b.p.shortNames = append(b.p.shortNames, strings.TrimLeft(n, "-"))
+	}
+	return b
+}
+
+// Inherit makes the parameter default to the value of the parameter with the
+// given key, such as a global setting in a parent struct, when it is not set
+// itself. The inherited value takes precedence over Default.
+func (b *URLBuilder) Inherit(key string) *URLBuilder {
+	b.p.parentKey = key
+	return b
+}
+
+// DenySource makes the parameter ignore values from sources of the given
+// kind, so the next source in priority order is used instead, e.g. to keep
+// a managed setting from being overridden on the command line.
+func (b *URLBuilder) DenySource(kind SourceKind) *URLBuilder {
+	b.p.denySource(kind, false)
+	return b
+}
+
+// RejectSource is like DenySource but makes Load fail with a
+// DeniedSourceError if a value comes from a source of the given kind.
+func (b *URLBuilder) RejectSource(kind SourceKind) *URLBuilder {
+	b.p.denySource(kind, true)
+	return b
+}
+
+// Schemes requires the URL to have one of the given schemes, compared
+// case-insensitively, e.g. Schemes("http", "https").
+func (b *URLBuilder) Schemes(schemes ...string) *URLBuilder {
+	b.p.validators = append(b.p.validators, func(u *url.URL) error {
+		if u == nil {
+			return nil
+		}
+		return checkScheme(u, schemes)
+	})
+	return b
+}
+
+// BindTo keeps the variable pointed to by ptr in sync with the parameter:
+// the default is written on Build and the loaded value on every successful
+// Load.
+func (b *URLBuilder) BindTo(ptr **url.URL) *URLBuilder {
+	b.p.bound = ptr
+	return b
+}
+
+func (b *URLBuilder) Validate(fn func(*url.URL) error) *URLBuilder {
+	b.p.validators = append(b.p.validators, fn)
+	return b
+}
+
+// WarnValidate adds an advisory validator: a failure is reported as a
+// warning and does not fail loading.
+func (b *URLBuilder) WarnValidate(fn func(*url.URL) error) *URLBuilder {
+	b.p.warnValidators = append(b.p.warnValidators, fn)
+	return b
+}
+
+func (b *URLBuilder) Build() URLParam {
+	b.p.mu = new(sync.RWMutex)
+	b.p.publish()
 	return b.p
 }
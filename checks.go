@@ -0,0 +1,357 @@
+package confetto
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// check is a relational constraint over several parameters. Checks run
+// after all parameters have been loaded, so they see the final values.
+type check func(params map[string]Param) error
+
+// runChecks evaluates all registered checks against the loaded parameters.
+func (l *Loader) runChecks(params []Param, loadErr *LoadError) {
+	if len(l.checks) == 0 {
+		return
+	}
+	byKey := make(map[string]Param, len(params))
+	for _, p := range params {
+		byKey[p.key()] = p
+	}
+	for _, c := range l.checks {
+		if err := c(byKey); err != nil {
+			loadErr.Add(err)
+		}
+	}
+}
+
+// RequireEqualLength requires the list parameters with the given keys to
+// have the same number of items, e.g. parallel lists of names and weights.
+func (l *Loader) RequireEqualLength(keys ...string) {
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		lengths := make([]int, len(keys))
+		for i, k := range keys {
+			p, ok := params[k]
+			if !ok {
+				return &UnknownKeyError{Key: k}
+			}
+			v := reflect.ValueOf(p.getAny())
+			if v.Kind() != reflect.Slice {
+				return &ValidationError{Key: k, Value: errorValue(p), Message: "not a list"}
+			}
+			lengths[i] = v.Len()
+		}
+		for i := 1; i < len(keys); i++ {
+			if lengths[i] != lengths[0] {
+				return &ValidationError{
+					Key:   keys[i],
+					Value: lengths[i],
+					Message: fmt.Sprintf(
+						"list has %d items but %q has %d", lengths[i], keys[0], lengths[0],
+					),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// RequireReferences requires the value of keyField to name an entry defined
+// under targetPrefix, that is the key segment right after the prefix of any
+// registered parameter. With pools.small.size and pools.large.size
+// registered, RequireReferences("default_pool", "pools") accepts "small" and
+// "large". The check is skipped when keyField has neither a value nor a
+// default.
+func (l *Loader) RequireReferences(keyField string, targetPrefix string) {
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		p, ok := params[keyField]
+		if !ok {
+			return &UnknownKeyError{Key: keyField}
+		}
+		if !p.IsSet() && !p.hasDefault() {
+			return nil
+		}
+		ref := p.stringValue()
+
+		var defined []string
+		for k := range params {
+			rest, ok := strings.CutPrefix(k, targetPrefix+".")
+			if !ok {
+				continue
+			}
+			name, _, _ := strings.Cut(rest, ".")
+			if !slices.Contains(defined, name) {
+				defined = append(defined, name)
+			}
+		}
+		if slices.Contains(defined, ref) {
+			return nil
+		}
+		slices.Sort(defined)
+		return &ValidationError{
+			Key:   keyField,
+			Value: errorValue(p),
+			Message: fmt.Sprintf(
+				"no entry %q defined under %q (defined: %v)",
+				errorString(p), targetPrefix, defined,
+			),
+		}
+	})
+}
+
+// RequireKeyOf requires the value of selectorKey to be one of the keys of
+// the map parameter mapKey, e.g. an active backend chosen among the entries
+// of a backends map. The check is skipped when selectorKey has neither a
+// value nor a default.
+func (l *Loader) RequireKeyOf(selectorKey string, mapKey string) {
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		sel, ok := params[selectorKey]
+		if !ok {
+			return &UnknownKeyError{Key: selectorKey}
+		}
+		m, ok := params[mapKey]
+		if !ok {
+			return &UnknownKeyError{Key: mapKey}
+		}
+		if !sel.IsSet() && !sel.hasDefault() {
+			return nil
+		}
+		v := reflect.ValueOf(m.getAny())
+		if v.Kind() != reflect.Map {
+			return &ValidationError{Key: mapKey, Value: errorValue(m), Message: "not a map"}
+		}
+		ref := sel.stringValue()
+		defined := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			defined = append(defined, fmt.Sprintf("%v", k.Interface()))
+		}
+		if slices.Contains(defined, ref) {
+			return nil
+		}
+		slices.Sort(defined)
+		return &ValidationError{
+			Key:   selectorKey,
+			Value: errorValue(sel),
+			Message: fmt.Sprintf(
+				"no key %q in %q (defined: %v)", errorString(sel), mapKey, defined,
+			),
+		}
+	})
+}
+
+// RequireTogether requires the parameters with the given keys to be set as
+// a group, e.g. a username and a password: once any of them has a value or
+// a default, each of the others without one is reported as a RequiredError.
+func (l *Loader) RequireTogether(keys ...string) {
+	for _, k := range keys {
+		l.checks = append(l.checks, func(params map[string]Param) error {
+			p, ok := params[k]
+			if !ok {
+				return &UnknownKeyError{Key: k}
+			}
+			if p.IsSet() || p.hasDefault() {
+				return nil
+			}
+			for _, other := range keys {
+				q, ok := params[other]
+				if ok && (q.IsSet() || q.hasDefault()) {
+					err := newRequiredError(l.opts, p)
+					err.Condition = fmt.Sprintf("%q is set", other)
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// RequireIf requires the parameters with the given keys to have a value or a
+// default when condition holds, reporting each missing one as a
+// RequiredError. The condition uses the RequireExpr syntax, so
+// RequireIf("tls.enabled", "tls.cert", "tls.key") requires both files only
+// when TLS is enabled.
+func (l *Loader) RequireIf(condition string, keys ...string) {
+	node, err := parseExpr(condition)
+	for i, k := range keys {
+		l.checks = append(l.checks, func(params map[string]Param) error {
+			if err != nil {
+				if i > 0 {
+					return nil // reported once, with the first key
+				}
+				return err
+			}
+			p, ok := params[k]
+			if !ok {
+				return &UnknownKeyError{Key: k}
+			}
+			if p.IsSet() || p.hasDefault() {
+				return nil
+			}
+			v, err := node.eval(params)
+			if err != nil || !v.truth {
+				return err
+			}
+			reqErr := newRequiredError(l.opts, p)
+			reqErr.Condition = condition
+			return reqErr
+		})
+	}
+}
+
+// RequireSameSource requires all of the given keys that are set to come from
+// the same source, so that a bundle such as a username and password cannot be
+// split between e.g. a CLI flag and the config file. Keys that are not set
+// are ignored.
+func (l *Loader) RequireSameSource(keys ...string) {
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		first := ""
+		for _, k := range keys {
+			p, ok := params[k]
+			if !ok {
+				return &UnknownKeyError{Key: k}
+			}
+			if !p.IsSet() {
+				continue
+			}
+			if first == "" {
+				first = k
+				continue
+			}
+			if p.origin() != params[first].origin() {
+				return &ValidationError{
+					Key:   k,
+					Value: p.origin(),
+					Message: fmt.Sprintf(
+						"set from %s but %q is set from %s",
+						p.origin(), first, params[first].origin(),
+					),
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// RequireLess requires the value of lower to be strictly less than the
+// value of upper, e.g. a minimum and maximum backoff. Both keys must hold
+// the same type, one of int, float64, time.Duration or time.Time. The check
+// is skipped when either key has neither a value nor a default.
+func (l *Loader) RequireLess(lower, upper string) {
+	l.checks = append(l.checks, orderCheck(lower, upper, false))
+}
+
+// RequireLessOrEqual is like RequireLess but also accepts equal values.
+func (l *Loader) RequireLessOrEqual(lower, upper string) {
+	l.checks = append(l.checks, orderCheck(lower, upper, true))
+}
+
+func orderCheck(lower, upper string, orEqual bool) check {
+	return func(params map[string]Param) error {
+		lo, ok := params[lower]
+		if !ok {
+			return &UnknownKeyError{Key: lower}
+		}
+		hi, ok := params[upper]
+		if !ok {
+			return &UnknownKeyError{Key: upper}
+		}
+		if (!lo.IsSet() && !lo.hasDefault()) || (!hi.IsSet() && !hi.hasDefault()) {
+			return nil
+		}
+		c, ok := compareValues(lo.getAny(), hi.getAny())
+		if !ok {
+			return &ValidationError{
+				Key:     lower,
+				Value:   errorValue(lo),
+				Message: fmt.Sprintf("cannot be compared with %q", upper),
+			}
+		}
+		if c < 0 || (orEqual && c == 0) {
+			return nil
+		}
+		rel := "less than"
+		if orEqual {
+			rel = "less than or equal to"
+		}
+		return &ValidationError{
+			Key:     lower,
+			Value:   errorValue(lo),
+			Message: fmt.Sprintf("must be %s %q (%v)", rel, upper, errorValue(hi)),
+		}
+	}
+}
+
+// RequireSumAtMost requires the sum of the int parameters with the given
+// keys to be at most limit, e.g. memory quotas sharing a budget. Parameters
+// holding int64 values, such as byte sizes, can be summed too. Keys with
+// neither a value nor a default count as zero. The sum is left out of the
+// error if any of them is Secret.
+func (l *Loader) RequireSumAtMost(limit int, keys ...string) {
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		var sum int64
+		secret := false
+		for _, k := range keys {
+			p, ok := params[k]
+			if !ok {
+				return &UnknownKeyError{Key: k}
+			}
+			if !p.IsSet() && !p.hasDefault() {
+				continue
+			}
+			switch v := p.getAny().(type) {
+			case int:
+				sum += int64(v)
+			case int64:
+				sum += v
+			default:
+				return &ValidationError{Key: k, Value: errorValue(p), Message: "not an integer"}
+			}
+			secret = secret || p.isSecret()
+		}
+		if sum <= int64(limit) {
+			return nil
+		}
+		if secret {
+			return &ValidationError{
+				Key:     strings.Join(keys, " + "),
+				Value:   maskedValue,
+				Message: fmt.Sprintf("sum exceeds the limit of %d", limit),
+			}
+		}
+		return &ValidationError{
+			Key:   strings.Join(keys, " + "),
+			Value: sum,
+			Message: fmt.Sprintf(
+				"sum %d exceeds the limit of %d by %d", sum, limit, sum-int64(limit),
+			),
+		}
+	})
+}
+
+// compareValues compares two values of the same ordered type, reporting
+// false if the types differ or are not supported.
+func compareValues(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case int:
+		if y, ok := b.(int); ok {
+			return cmp.Compare(x, y), true
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y), true
+		}
+	case time.Duration:
+		if y, ok := b.(time.Duration); ok {
+			return cmp.Compare(x, y), true
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	}
+	return 0, false
+}
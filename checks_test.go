@@ -0,0 +1,377 @@
+package confetto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoader_RequireEqualLength(t *testing.T) {
+	type shardConfig struct {
+		Names   StringListParam `cfg:"shard_names"`
+		Weights IntListParam    `cfg:"shard_weights"`
+	}
+
+	load := func(args []string, keys ...string) error {
+		cfg := shardConfig{
+			Names:   StringList().Build(),
+			Weights: IntList().Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		l.RequireEqualLength(keys...)
+		return l.Load()
+	}
+
+	t.Run("Equal", func(t *testing.T) {
+		err := load(
+			[]string{"--shard_names=a,b", "--shard_weights=1,2"},
+			"shard_names", "shard_weights",
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		err := load(
+			[]string{"--shard_names=a,b,c", "--shard_weights=1,2"},
+			"shard_names", "shard_weights",
+		)
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		var ve *ValidationError
+		if !errors.As(loadErr.Errors[0], &ve) {
+			t.Fatalf("expected ValidationError, got %v", loadErr.Errors[0])
+		}
+		if ve.Key != "shard_weights" {
+			t.Errorf("expected key shard_weights, got %s", ve.Key)
+		}
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		err := load(nil, "shard_names", "shard_sizes")
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		var uke *UnknownKeyError
+		if !errors.As(loadErr.Errors[0], &uke) {
+			t.Fatalf("expected UnknownKeyError, got %v", loadErr.Errors[0])
+		}
+	})
+}
+
+func TestLoader_RequireReferences(t *testing.T) {
+	type poolConfig struct {
+		Size IntParam `cfg:"size"`
+	}
+	type poolsConfig struct {
+		DefaultPool StringParam `cfg:"default_pool"`
+		Pools       struct {
+			Small poolConfig `cfg:"small"`
+			Large poolConfig `cfg:"large"`
+		} `cfg:"pools"`
+	}
+
+	load := func(args []string) error {
+		var cfg poolsConfig
+		cfg.DefaultPool = String().Default("small").Build()
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		l.RequireReferences("default_pool", "pools")
+		return l.Load()
+	}
+
+	if err := load(nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := load([]string{"--default_pool=large"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := load([]string{"--default_pool=medium"})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) {
+		t.Fatalf("expected ValidationError, got %v", loadErr.Errors[0])
+	}
+	if ve.Key != "default_pool" || !strings.Contains(ve.Message, "[large small]") {
+		t.Errorf("unexpected error: %v", ve)
+	}
+}
+
+func TestLoader_RequireSameSource(t *testing.T) {
+	type credsConfig struct {
+		Username StringParam `cfg:"username"`
+		Password StringParam `cfg:"password"`
+	}
+
+	load := func(args []string) error {
+		cfg := credsConfig{
+			Username: String().Build(),
+			Password: String().Secret().Build(),
+		}
+		l := NewLoader(Options{
+			Args:      args,
+			ConfigMap: map[string]any{"password": "from-file"},
+		})
+		l.Register("", &cfg)
+		l.RequireSameSource("username", "password")
+		return l.Load()
+	}
+
+	if err := load([]string{"--username=admin", "--password=secret"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := load(nil); err != nil {
+		t.Fatalf("unexpected error with a single key set: %v", err)
+	}
+
+	err := load([]string{"--username=admin"})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) {
+		t.Fatalf("expected ValidationError, got %v", loadErr.Errors[0])
+	}
+	if ve.Key != "password" || !strings.Contains(ve.Message, "set from map") {
+		t.Errorf("unexpected error: %v", ve)
+	}
+}
+
+func TestLoader_RequireLess(t *testing.T) {
+	type backoffConfig struct {
+		MinBackoff DurationParam `cfg:"min_backoff"`
+		MaxBackoff DurationParam `cfg:"max_backoff"`
+	}
+
+	load := func(args []string, orEqual bool) error {
+		cfg := backoffConfig{
+			MinBackoff: Duration().Default(100 * time.Millisecond).Build(),
+			MaxBackoff: Duration().Default(30 * time.Second).Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		if orEqual {
+			l.RequireLessOrEqual("min_backoff", "max_backoff")
+		} else {
+			l.RequireLess("min_backoff", "max_backoff")
+		}
+		return l.Load()
+	}
+
+	if err := load(nil, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	equal := []string{"--min_backoff=1s", "--max_backoff=1s"}
+	if err := load(equal, true); err != nil {
+		t.Fatalf("unexpected error with equal values: %v", err)
+	}
+	if err := load(equal, false); err == nil {
+		t.Fatal("expected error with equal values")
+	}
+
+	err := load([]string{"--min_backoff=10s", "--max_backoff=1s"}, true)
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) {
+		t.Fatalf("expected ValidationError, got %v", loadErr.Errors[0])
+	}
+	if ve.Key != "min_backoff" || !strings.Contains(ve.Message, `"max_backoff"`) {
+		t.Errorf("unexpected error: %v", ve)
+	}
+}
+
+func TestLoader_RequireLess_MismatchedTypes(t *testing.T) {
+	type config struct {
+		Min IntParam      `cfg:"min"`
+		Max DurationParam `cfg:"max"`
+	}
+	cfg := config{
+		Min: Int().Default(1).Build(),
+		Max: Duration().Default(time.Second).Build(),
+	}
+	l := NewLoader(Options{})
+	l.Register("", &cfg)
+	l.RequireLess("min", "max")
+	var loadErr *LoadError
+	if err := l.Load(); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) || !strings.Contains(ve.Message, "compared") {
+		t.Errorf("expected comparison error, got %v", loadErr.Errors[0])
+	}
+}
+
+func TestLoader_RequireSumAtMost(t *testing.T) {
+	type quotas struct {
+		Cache   IntParam `cfg:"cache"`
+		Queue   IntParam `cfg:"queue"`
+		Workers IntParam `cfg:"workers"`
+	}
+	load := func(args ...string) error {
+		cfg := quotas{
+			Cache:   Int().Default(512).Build(),
+			Queue:   Int().Default(256).Build(),
+			Workers: Int().Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("mem", &cfg)
+		l.RequireSumAtMost(1024, "mem.cache", "mem.queue", "mem.workers")
+		return l.Load()
+	}
+
+	if err := load(); err != nil {
+		t.Errorf("unexpected error with unset key: %v", err)
+	}
+	if err := load("--mem.workers=256"); err != nil {
+		t.Errorf("unexpected error at the limit: %v", err)
+	}
+
+	var loadErr *LoadError
+	if err := load("--mem.workers=300"); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) {
+		t.Fatalf("expected ValidationError, got %v", loadErr.Errors[0])
+	}
+	if ve.Value != int64(1068) || !strings.Contains(ve.Message, "by 44") {
+		t.Errorf("unexpected error: %v", ve)
+	}
+}
+
+func TestLoader_RequireKeyOf(t *testing.T) {
+	type config struct {
+		Backends MapStringParam `cfg:"backends"`
+		Active   StringParam    `cfg:"active_backend"`
+	}
+	load := func(args ...string) error {
+		cfg := config{
+			Backends: MapString().Default(map[string]string{
+				"primary": "db1:5432", "replica": "db2:5432",
+			}).Build(),
+			Active: String().Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		l.RequireKeyOf("active_backend", "backends")
+		return l.Load()
+	}
+
+	if err := load(); err != nil {
+		t.Errorf("unexpected error with unset selector: %v", err)
+	}
+	if err := load("--active_backend=replica"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var loadErr *LoadError
+	if err := load("--active_backend=standby"); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) {
+		t.Fatalf("expected ValidationError, got %v", loadErr.Errors[0])
+	}
+	if ve.Key != "active_backend" || !strings.Contains(ve.Message, "[primary replica]") {
+		t.Errorf("unexpected error: %v", ve)
+	}
+}
+
+func TestLoader_RequireTogether(t *testing.T) {
+	type config struct {
+		User     StringParam `cfg:"user"`
+		Password StringParam `cfg:"password"`
+		Token    StringParam `cfg:"token"`
+	}
+	load := func(args ...string) error {
+		cfg := config{User: String().Build(), Password: String().Build(), Token: String().Build()}
+		l := NewLoader(Options{Args: args, EnvPrefix: "APP"})
+		l.Register("", &cfg)
+		l.RequireTogether("user", "password", "token")
+		return l.Load()
+	}
+
+	if err := load(); err != nil {
+		t.Errorf("unexpected error with none set: %v", err)
+	}
+	if err := load("--user=u", "--password=p", "--token=t"); err != nil {
+		t.Errorf("unexpected error with all set: %v", err)
+	}
+
+	var loadErr *LoadError
+	if err := load("--password=p"); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	if len(loadErr.Errors) != 2 {
+		t.Fatalf("expected an error per missing key, got %v", loadErr.Errors)
+	}
+	var re *RequiredError
+	if !errors.As(loadErr.Errors[0], &re) || re.Key != "user" || re.EnvVar != "APP_USER" {
+		t.Fatalf("expected RequiredError for user, got %v", loadErr.Errors[0])
+	}
+	want := `required parameter "user" is not set when "password" is set (set APP_USER or --user)`
+	if re.Error() != want {
+		t.Errorf("unexpected message: %s", re.Error())
+	}
+}
+
+func TestLoader_RequireIf(t *testing.T) {
+	type config struct {
+		Enabled BoolParam   `cfg:"tls.enabled"`
+		Cert    StringParam `cfg:"tls.cert"`
+		Key     StringParam `cfg:"tls.key"`
+	}
+	load := func(condition string, args ...string) error {
+		cfg := config{
+			Enabled: Bool().Default(false).Build(),
+			Cert:    String().Build(),
+			Key:     String().Default("key.pem").Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		l.RequireIf(condition, "tls.cert", "tls.key")
+		return l.Load()
+	}
+
+	if err := load("tls.enabled"); err != nil {
+		t.Errorf("unexpected error with condition false: %v", err)
+	}
+	if err := load("tls.enabled", "--tls.enabled", "--tls.cert=cert.pem"); err != nil {
+		t.Errorf("unexpected error with dependents set: %v", err)
+	}
+
+	var loadErr *LoadError
+	if err := load("tls.enabled", "--tls.enabled"); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var re *RequiredError
+	if len(loadErr.Errors) != 1 || !errors.As(loadErr.Errors[0], &re) {
+		t.Fatalf("expected a single RequiredError, got %v", loadErr.Errors)
+	}
+	if re.Key != "tls.cert" || re.Condition != "tls.enabled" {
+		t.Errorf("unexpected error: %+v", re)
+	}
+
+	if err := load("tls.enabled &&", "--tls.enabled"); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ee *ExprError
+	if len(loadErr.Errors) != 1 || !errors.As(loadErr.Errors[0], &ee) {
+		t.Errorf("expected a single ExprError, got %v", loadErr.Errors)
+	}
+}
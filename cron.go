@@ -0,0 +1,119 @@
+package confetto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cronField describes one field of a cron expression.
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names for min, min+1, ... such as JAN or SUN
+}
+
+//nolint:gochecknoglobals // fixed field tables
+var (
+	cronSeconds = cronField{name: "second", min: 0, max: 59}
+	cronFields  = []cronField{
+		{name: "minute", min: 0, max: 59},
+		{name: "hour", min: 0, max: 23},
+		{name: "day of month", min: 1, max: 31},
+		{name: "month", min: 1, max: 12, names: []string{
+			"JAN", "FEB", "MAR", "APR", "MAY", "JUN",
+			"JUL", "AUG", "SEP", "OCT", "NOV", "DEC",
+		}},
+		// 7 is Sunday too
+		{name: "day of week", min: 0, max: 7, names: []string{
+			"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT",
+		}},
+	}
+	cronDescriptors = []string{
+		"@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly",
+	}
+)
+
+// CronExpr validates a cron expression with 5 fields (minute, hour, day of
+// month, month, day of week) or 6 fields with leading seconds. Each field
+// is *, a value, a range a-b, or a comma separated list of those, each
+// optionally followed by a /step. Months and days of the week also accept
+// three letter names such as JAN or MON, and descriptors such as @daily are
+// accepted as a whole expression.
+func CronExpr() func(string) error {
+	return func(v string) error {
+		fields := strings.Fields(v)
+		if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+			for _, d := range cronDescriptors {
+				if strings.EqualFold(fields[0], d) {
+					return nil
+				}
+			}
+			return fmt.Errorf("%w: unknown cron descriptor %q", ErrValidation, fields[0])
+		}
+		specs := cronFields
+		switch len(fields) {
+		case len(cronFields):
+		case len(cronFields) + 1:
+			specs = append([]cronField{cronSeconds}, cronFields...)
+		default:
+			return fmt.Errorf(
+				"%w: cron expression has %d fields, expected 5 or 6", ErrValidation, len(fields),
+			)
+		}
+		for i, f := range fields {
+			if err := specs[i].check(f); err != nil {
+				return fmt.Errorf("%w: invalid cron %s field %q: %v", ErrValidation, specs[i].name, f, err)
+			}
+		}
+		return nil
+	}
+}
+
+// check validates one field of a cron expression.
+func (c cronField) check(field string) error {
+	for item := range strings.SplitSeq(field, ",") {
+		rng, step, hasStep := strings.Cut(item, "/")
+		if hasStep {
+			if n, err := strconv.Atoi(step); err != nil || n <= 0 {
+				return fmt.Errorf("step %q is not a positive number", step)
+			}
+		}
+		if rng == "*" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(rng, "-")
+		from, err := c.value(lo)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		to, err := c.value(hi)
+		if err != nil {
+			return err
+		}
+		if from > to {
+			return fmt.Errorf("range %s is reversed", rng)
+		}
+	}
+	return nil
+}
+
+// value parses a single value or name and checks it is in range.
+func (c cronField) value(s string) (int, error) {
+	for i, name := range c.names {
+		if strings.EqualFold(s, name) {
+			return c.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+	if n < c.min || n > c.max {
+		return 0, fmt.Errorf("%d is not in range [%d, %d]", n, c.min, c.max)
+	}
+	return n, nil
+}
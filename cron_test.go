@@ -0,0 +1,49 @@
+package confetto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCronExpr(t *testing.T) {
+	v := CronExpr()
+
+	valid := []string{
+		"* * * * *",
+		"*/15 0-6,18-23 1 JAN-MAR mon-fri",
+		"0 30 9 * * 1-5",
+		"5 4 * * 7",
+		"@daily",
+		"@Hourly",
+	}
+	for _, expr := range valid {
+		if err := v(expr); err != nil {
+			t.Errorf("%q: unexpected error: %v", expr, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"* * * *":       "has 4 fields",
+		"60 * * * *":    "minute field",
+		"* 24 * * *":    "hour field",
+		"* * 0 * *":     "day of month field",
+		"* * * FOO *":   "month field",
+		"* * * * 1-8":   "day of week field",
+		"*/0 * * * *":   "not a positive number",
+		"* 5-2 * * *":   "reversed",
+		"61 * * * * *":  "second field",
+		"@sometimes":    "unknown cron descriptor",
+		"0 0 1,,15 * *": `"" is not a number`,
+	}
+	for expr, want := range invalid {
+		err := v(expr)
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("%q: expected validation error, got %v", expr, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected error mentioning %q, got %v", expr, want, err)
+		}
+	}
+}
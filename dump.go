@@ -1,40 +1,437 @@
 package confetto
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 const maskedValue = "****"
 
+// errKeyConflict is returned by DumpJSON when a key is also the parent of
+// other keys, e.g. both db and db.host, which a nested object cannot hold.
+var errKeyConflict = errors.New("key is both a value and a parent of other keys")
+
 // Dump returns a string representation of all configuration parameters
 // in the provided struct. Secret parameters are masked with "****".
 // Parameters that are not set and have no default value are shown as "<not set>".
 func Dump(cfg any) string {
-	return dumpParams(collectParams(cfg, ""))
+	return dumpParams(collectParams(cfg, ""), false)
 }
 
 // Dump returns a string representation of all configuration parameters
 // across all registered configs. Secret parameters are masked with "****".
 // Parameters that are not set and have no default value are shown as "<not set>".
 func (l *Loader) Dump() string {
-	return dumpParams(l.collectAllParams())
+	return dumpParams(l.collectAllParams(), false)
 }
 
-func dumpParams(params []Param) string {
+// DumpByModule is like Dump but groups the output by registration, with a
+// "[prefix]" header before the parameters of each registered config.
+// Registrations with an empty prefix use the "[(root)]" header.
+func (l *Loader) DumpByModule() string {
 	var b strings.Builder
-	for i, p := range params {
+	for i, r := range l.registrations {
 		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		header := r.prefix
+		if header == "" {
+			header = "(root)"
+		}
+		b.WriteString("[" + header + "]")
+		if params := collectParams(r.cfg, r.prefix); len(params) > 0 {
 			b.WriteByte('\n')
+			b.WriteString(dumpParams(params, false))
+		}
+	}
+	return b.String()
+}
+
+// DumpWithSources is like Dump but ends each line with the kind of source
+// that set the value, e.g. "db.host = x (env)", to show which source won.
+func DumpWithSources(cfg any) string {
+	return dumpParams(collectParams(cfg, ""), true)
+}
+
+// DumpWithSources is like Loader.Dump but ends each line with the kind of
+// source that set the value, e.g. "db.host = x (env)".
+func (l *Loader) DumpWithSources() string {
+	return dumpParams(l.collectAllParams(), true)
+}
+
+func dumpParams(params []Param, sources bool) string {
+	var b strings.Builder
+	_ = writeParams(&b, params, sources) // strings.Builder never fails
+	return b.String()
+}
+
+// DumpTo is like Dump but writes the parameters to w one line at a time,
+// without building the whole dump in memory first.
+func DumpTo(w io.Writer, cfg any) error {
+	return writeParams(w, collectParams(cfg, ""), false)
+}
+
+// DumpTo is like Loader.Dump but writes the parameters to w one line at a
+// time, without building the whole dump in memory first.
+func (l *Loader) DumpTo(w io.Writer) error {
+	return writeParams(w, l.collectAllParams(), false)
+}
+
+// writeParams writes one "key = value" line per parameter to w, with no
+// newline after the last one. If sources is true, lines of params with a
+// value end with the kind of their source.
+func writeParams(w io.Writer, params []Param, sources bool) error {
+	for i, p := range params {
+		sep := "\n"
+		if i == 0 {
+			sep = ""
+		}
+		var value string
+		switch {
+		case p.isSecret():
+			value = maskedValue
+		case !p.IsSet() && !p.hasDefault():
+			value = "<not set>"
+		case p.unit() != "":
+			value = p.stringValue() + " (" + p.unit() + ")"
+		default:
+			value = p.stringValue()
+		}
+		if sources && (p.IsSet() || p.hasDefault()) {
+			value += " (" + p.Source().String() + ")"
 		}
+		if _, err := io.WriteString(w, sep+p.key()+" = "+value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Canonical returns a normalized representation of all configuration
+// parameters in the provided struct, for reviewing changes as diffs. Unlike
+// Dump, keys are sorted lexicographically, so reordering struct fields does
+// not change the output. Each line is terminated by a newline. Strings are
+// single-quoted like shell words, durations use their String form and lists
+// are written as ['a', 'b']. Secret parameters are masked with "****".
+func Canonical(cfg any) string {
+	params := collectParams(cfg, "")
+	slices.SortFunc(params, func(a, b Param) int {
+		return strings.Compare(a.key(), b.key())
+	})
+
+	var b strings.Builder
+	for _, p := range params {
 		b.WriteString(p.key())
 		b.WriteString(" = ")
-		if p.isSecret() {
+		switch {
+		case p.isSecret():
 			b.WriteString(maskedValue)
-		} else if !p.IsSet() && !p.hasDefault() {
+		case !p.IsSet() && !p.hasDefault():
 			b.WriteString("<not set>")
-		} else {
-			b.WriteString(p.stringValue())
+		default:
+			b.WriteString(canonicalValue(p))
 		}
+		b.WriteByte('\n')
 	}
 	return b.String()
 }
+
+// canonicalValue formats the value of p for Canonical.
+func canonicalValue(p Param) string {
+	v := reflect.ValueOf(p.getAny())
+	switch {
+	case v.Kind() == reflect.String:
+		return singleQuote(v.String())
+	case isList(p):
+		return canonicalList(v)
+	default:
+		return p.stringValue()
+	}
+}
+
+// canonicalList formats a possibly nested list as ['a', 'b'] or [1, 2].
+func canonicalList(v reflect.Value) string {
+	items := make([]string, v.Len())
+	for i := range items {
+		switch item := v.Index(i); item.Kind() {
+		case reflect.String:
+			items[i] = singleQuote(item.String())
+		case reflect.Slice:
+			items[i] = canonicalList(item)
+		default:
+			items[i] = fmt.Sprintf("%v", item.Interface())
+		}
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// DumpKV returns the parameters in the provided struct as "key = value"
+// lines that ParseDump reads back, so that loading the result reproduces
+// the same values. Values are single-line YAML: strings are double-quoted
+// and lists use the [a, b] flow form. Parameters with neither a value nor a
+// default are omitted, and so are secret parameters, which never leave the
+// process.
+func DumpKV(cfg any) (string, error) {
+	return dumpKV(collectParams(cfg, ""))
+}
+
+// DumpKV is like the package-level DumpKV but covers all registered configs.
+func (l *Loader) DumpKV() (string, error) {
+	return dumpKV(l.collectAllParams())
+}
+
+func dumpKV(params []Param) (string, error) {
+	var b strings.Builder
+	for _, p := range params {
+		if p.isSecret() || (!p.IsSet() && !p.hasDefault()) {
+			continue
+		}
+		var node yaml.Node
+		if err := node.Encode(exportValue(p)); err != nil {
+			return "", err
+		}
+		flowStyle(&node)
+		out, err := yaml.Marshal(&node)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(p.key() + " = " + strings.TrimSuffix(string(out), "\n") + "\n")
+	}
+	return b.String(), nil
+}
+
+// flowStyle makes a node encode on a single line, with double-quoted
+// strings and flow sequences and mappings.
+func flowStyle(n *yaml.Node) {
+	switch n.Kind {
+	case yaml.SequenceNode, yaml.MappingNode:
+		n.Style = yaml.FlowStyle
+		for _, c := range n.Content {
+			flowStyle(c)
+		}
+	case yaml.ScalarNode:
+		if n.Tag == "!!str" {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+	}
+}
+
+// ParseDump parses the output of DumpKV into a document for
+// Options.ConfigMap, so a dump can be loaded back. Empty lines and lines
+// starting with "#" are ignored.
+func ParseDump(s string) (map[string]any, error) {
+	doc := make(map[string]any)
+	for i, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+		if !ok || key == "" {
+			return nil, &ParseError{
+				Key: fmt.Sprintf("line %d", i+1), Value: line, Expected: "key = value",
+			}
+		}
+		var v any
+		if err := yaml.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, &ParseError{Key: key, Value: raw, Expected: "YAML value", Err: err}
+		}
+		if !setPath(doc, strings.Split(key, "."), v) {
+			return nil, &ParseError{Key: key, Value: raw, Expected: "value for a unique key"}
+		}
+	}
+	return doc, nil
+}
+
+// setPath stores v in the nested map m at the given path, reporting false
+// if the path is already taken by another value.
+func setPath(m map[string]any, path []string, v any) bool {
+	for _, part := range path[:len(path)-1] {
+		child, exists := m[part]
+		if !exists {
+			child = make(map[string]any)
+			m[part] = child
+		}
+		next, ok := child.(map[string]any)
+		if !ok {
+			return false
+		}
+		m = next
+	}
+	last := path[len(path)-1]
+	if _, exists := m[last]; exists {
+		return false
+	}
+	m[last] = v
+	return true
+}
+
+// DumpJSON returns the parameters in the provided struct as a nested JSON
+// object following the dotted keys, so db.host becomes {"db":{"host":...}}.
+// Values keep their types: numbers and booleans are JSON numbers and
+// booleans, lists are arrays, and durations and other types use their
+// string form. Secret parameters are masked with "****" and parameters with
+// neither a value nor a default are null.
+func DumpJSON(cfg any) ([]byte, error) {
+	return dumpJSON(collectParams(cfg, ""))
+}
+
+// DumpJSON is like the package-level DumpJSON but covers all registered
+// configs.
+func (l *Loader) DumpJSON() ([]byte, error) {
+	return dumpJSON(l.collectAllParams())
+}
+
+func dumpJSON(params []Param) ([]byte, error) {
+	doc := make(map[string]any)
+	for _, p := range params {
+		var v any
+		switch {
+		case p.isSecret():
+			v = maskedValue
+		case !p.IsSet() && !p.hasDefault():
+			v = nil
+		default:
+			v = exportValue(p)
+		}
+		if !setPath(doc, strings.Split(p.key(), "."), v) {
+			return nil, fmt.Errorf("%s: %w", p.key(), errKeyConflict)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// DumpEnv returns the parameters in the provided struct as NAME=value lines
+// for environment variables with the given prefix, e.g. APP_DB_HOST=x, that
+// can be pasted into a shell or a .env file. Lists are joined with ",", and
+// values containing spaces or shell metacharacters are quoted. Parameters
+// with neither a value nor a default are omitted, and secret values are
+// masked with "****"; use DumpEnvWithSecrets to include them.
+func DumpEnv(cfg any, prefix string) string {
+	return dumpEnv(collectParams(cfg, ""), prefix, false)
+}
+
+// DumpEnvWithSecrets is like DumpEnv but writes secret values in clear text.
+func DumpEnvWithSecrets(cfg any, prefix string) string {
+	return dumpEnv(collectParams(cfg, ""), prefix, true)
+}
+
+func dumpEnv(params []Param, prefix string, secrets bool) string {
+	var b strings.Builder
+	for _, p := range params {
+		if !p.IsSet() && !p.hasDefault() {
+			continue
+		}
+		value := maskedValue
+		if secrets || !p.isSecret() {
+			value = shellQuote(envValue(p))
+		}
+		b.WriteString(paramEnvName(prefix, p) + "=" + value + "\n")
+	}
+	return b.String()
+}
+
+// envValue formats the value of p the way it is read from an environment
+// variable, with list items joined by the default separator.
+func envValue(p Param) string {
+	v := reflect.ValueOf(exportValue(p))
+	switch v.Kind() {
+	case reflect.Slice:
+	case reflect.Map:
+		return p.stringValue()
+	default:
+		return fmt.Sprintf("%v", v.Interface())
+	}
+	inner := ":"
+	if np, ok := p.(*NestedStringListParam); ok {
+		inner = np.innerSeparator()
+	}
+	items := make([]string, v.Len())
+	for i := range items {
+		item := v.Index(i)
+		if item.Kind() == reflect.Slice {
+			fields := make([]string, item.Len())
+			for j := range fields {
+				fields[j] = fmt.Sprintf("%v", item.Index(j).Interface())
+			}
+			items[i] = strings.Join(fields, inner)
+			continue
+		}
+		items[i] = fmt.Sprintf("%v", item.Interface())
+	}
+	return strings.Join(items, ",")
+}
+
+// shellQuote quotes s with singleQuote if it is empty or contains spaces
+// or characters special to the shell.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n\"'$`\\#;&|<>()*?[]{}!~") {
+		return s
+	}
+	return singleQuote(s)
+}
+
+// singleQuote wraps s in single quotes so that a shell reads it back
+// literally. Each embedded single quote closes the quoting, adds an escaped
+// quote and reopens it:
+//
+//	'\''
+func singleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Summary is an aggregate view of the configuration after Load, suitable
+// for a config-health endpoint.
+type Summary struct {
+	// Total is the number of registered parameters.
+	Total int
+	// Set counts parameters whose value came from a source.
+	Set int
+	// Defaulted counts parameters that are not set but have a default.
+	Defaulted int
+	// Unset counts parameters with neither a value nor a default.
+	Unset int
+	// Secret counts parameters marked as secret.
+	Secret int
+	// UnsetKeys lists the keys of the unset parameters.
+	UnsetKeys []string
+	// WarningKeys lists the keys with at least one warning.
+	WarningKeys []string
+	// Warnings are the warnings reported by the last Load.
+	Warnings []Warning
+}
+
+// Summary returns counts of set, defaulted, unset and secret parameters
+// across all registered configs, along with the keys needing attention.
+func (l *Loader) Summary() Summary {
+	params := l.collectAllParams()
+	s := Summary{Total: len(params), Warnings: l.Warnings()}
+	for _, p := range params {
+		switch {
+		case p.IsSet():
+			s.Set++
+		case p.hasDefault():
+			s.Defaulted++
+		default:
+			s.Unset++
+			s.UnsetKeys = append(s.UnsetKeys, p.key())
+		}
+		if p.isSecret() {
+			s.Secret++
+		}
+	}
+	for _, w := range s.Warnings {
+		if !slices.Contains(s.WarningKeys, w.Key) {
+			s.WarningKeys = append(s.WarningKeys, w.Key)
+		}
+	}
+	return s
+}
@@ -1,6 +1,11 @@
 package confetto
 
 import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -12,9 +17,9 @@ func TestDump(t *testing.T) {
 		Name     StringParam `cfg:"name"`
 	}
 	type Config struct {
-		Host string      `cfg:"host"`
-		Port IntParam    `cfg:"port"`
-		DB   DB          `cfg:"db"`
+		Host string   `cfg:"host"`
+		Port IntParam `cfg:"port"`
+		DB   DB       `cfg:"db"`
 	}
 
 	cfg := Config{
@@ -58,6 +63,36 @@ func TestDumpNotSet(t *testing.T) {
 	}
 }
 
+func TestDumpWithSources(t *testing.T) {
+	t.Setenv("SRC_PORT", "9090")
+
+	type Config struct {
+		Host StringParam `cfg:"host"`
+		Port IntParam    `cfg:"port"`
+		Name StringParam `cfg:"name"`
+		User StringParam `cfg:"user"`
+	}
+	cfg := Config{
+		Port: Int().Default(3000).Build(),
+		Name: String().Default("app").Build(),
+	}
+	err := Load(&cfg, Options{Args: []string{"--host=example.com"}, EnvPrefix: "SRC"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := DumpWithSources(&cfg)
+	expected := "host = example.com (cli)\nport = 9090 (env)\n" +
+		"name = app (default)\nuser = <not set>"
+	if got != expected {
+		t.Errorf("DumpWithSources() =\n%s\nwant:\n%s", got, expected)
+	}
+	if cfg.Host.Source() != SourceCLI || cfg.User.Source() != SourceDefault {
+		t.Errorf("expected cli and default, got %v and %v",
+			cfg.Host.Source(), cfg.User.Source())
+	}
+}
+
 func TestDumpAllTypes(t *testing.T) {
 	type Config struct {
 		S  StringParam       `cfg:"s"`
@@ -118,3 +153,416 @@ func TestDumpEmpty(t *testing.T) {
 		t.Errorf("Dump() = %q, want empty string", got)
 	}
 }
+
+func TestLoader_DumpByModule(t *testing.T) {
+	type DB struct {
+		Host     StringParam `cfg:"host"`
+		Password StringParam `cfg:"password"`
+	}
+	type Server struct {
+		Addr StringParam `cfg:"addr"`
+	}
+	type Global struct {
+		Debug BoolParam `cfg:"debug"`
+	}
+
+	db := DB{
+		Host:     String().Default("localhost").Build(),
+		Password: String().Secret().Build(),
+	}
+	server := Server{Addr: String().Default(":8080").Build()}
+	global := Global{Debug: Bool().Default(false).Build()}
+
+	l := NewLoader(Options{})
+	l.Register("", &global)
+	l.Register("db", &db)
+	l.Register("server", &server)
+
+	got := l.DumpByModule()
+	expected := "[(root)]\ndebug = false\n\n" +
+		"[db]\ndb.host = localhost\ndb.password = ****\n\n" +
+		"[server]\nserver.addr = :8080"
+	if got != expected {
+		t.Errorf("DumpByModule() =\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestLoader_Summary(t *testing.T) {
+	type Config struct {
+		Host     StringParam `cfg:"host"`
+		Port     IntParam    `cfg:"port"`
+		Password StringParam `cfg:"password"`
+		Name     StringParam `cfg:"name"`
+		Percent  IntParam    `cfg:"percent"`
+	}
+
+	cfg := Config{
+		Host:     String().Build(),
+		Port:     Int().Default(8080).Build(),
+		Password: String().Secret().Build(),
+		Name:     String().Build(),
+		Percent:  Int().Clamp(0, 100).WarnValidate(Range(0, 50)).Build(),
+	}
+	l := NewLoader(Options{Args: []string{"--host=example.com", "--percent=150"}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := l.Summary()
+	if s.Total != 5 || s.Set != 2 || s.Defaulted != 1 || s.Unset != 2 || s.Secret != 1 {
+		t.Errorf("unexpected counts: %+v", s)
+	}
+	if len(s.UnsetKeys) != 2 || s.UnsetKeys[0] != "password" || s.UnsetKeys[1] != "name" {
+		t.Errorf("unexpected unset keys: %v", s.UnsetKeys)
+	}
+	if len(s.Warnings) != 2 || len(s.WarningKeys) != 1 || s.WarningKeys[0] != "percent" {
+		t.Errorf("unexpected warnings: %v %v", s.Warnings, s.WarningKeys)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	type Config struct {
+		Timeout  DurationParam   `cfg:"timeout"`
+		Name     StringParam     `cfg:"name"`
+		Password StringParam     `cfg:"password"`
+		Tags     StringListParam `cfg:"tags"`
+		Ports    IntListParam    `cfg:"ports"`
+		Missing  IntParam        `cfg:"missing"`
+	}
+	type Reordered struct {
+		Ports    IntListParam    `cfg:"ports"`
+		Missing  IntParam        `cfg:"missing"`
+		Tags     StringListParam `cfg:"tags"`
+		Password StringParam     `cfg:"password"`
+		Name     StringParam     `cfg:"name"`
+		Timeout  DurationParam   `cfg:"timeout"`
+	}
+
+	cfg := Config{
+		Timeout:  Duration().Default(90 * time.Second).Build(),
+		Name:     String().Default("my app").Build(),
+		Password: String().Default("hunter2").Secret().Build(),
+		Tags:     StringList().Default([]string{"a b", "c"}).Build(),
+		Ports:    IntList().Default([]int{80, 443}).Build(),
+		Missing:  Int().Build(),
+	}
+	reordered := Reordered{
+		Ports:    cfg.Ports,
+		Missing:  cfg.Missing,
+		Tags:     cfg.Tags,
+		Password: cfg.Password,
+		Name:     cfg.Name,
+		Timeout:  cfg.Timeout,
+	}
+
+	expected := `missing = <not set>
+name = 'my app'
+password = ****
+ports = [80, 443]
+tags = ['a b', 'c']
+timeout = 1m30s
+`
+	if got := Canonical(&cfg); got != expected {
+		t.Errorf("unexpected output:\n%s", got)
+	}
+	if got := Canonical(&reordered); got != expected {
+		t.Errorf("expected output independent of field order, got:\n%s", got)
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestDumpTo(t *testing.T) {
+	type Config struct {
+		Host     StringParam `cfg:"host"`
+		Password StringParam `cfg:"password"`
+		Port     IntParam    `cfg:"port"`
+	}
+	cfg := Config{
+		Host:     String().Default("localhost").Build(),
+		Password: String().Default("s3cret").Secret().Build(),
+		Port:     Int().Build(),
+	}
+
+	var b strings.Builder
+	if err := DumpTo(&b, &cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != Dump(&cfg) {
+		t.Errorf("expected same output as Dump, got:\n%s", b.String())
+	}
+
+	l := NewLoader(Options{})
+	l.Register("db", &cfg)
+	b.Reset()
+	if err := l.DumpTo(&b); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.String() != l.Dump() {
+		t.Errorf("expected same output as Loader.Dump, got:\n%s", b.String())
+	}
+
+	if err := DumpTo(failingWriter{}, &cfg); err == nil {
+		t.Error("expected write error")
+	}
+}
+
+func TestDump_Unit(t *testing.T) {
+	type Config struct {
+		Timeout IntParam       `cfg:"timeout"`
+		Rate    FloatParam     `cfg:"rate"`
+		Sizes   IntListParam   `cfg:"sizes"`
+		Retries IntParam       `cfg:"retries"`
+		Limit   FloatListParam `cfg:"limit"`
+	}
+	cfg := Config{
+		Timeout: Int().Default(30).Unit("seconds").Build(),
+		Rate:    Float().Default(2.5).Unit("requests/s").Build(),
+		Sizes:   IntList().Default([]int{1, 2}).Unit("MiB").Build(),
+		Retries: Int().Default(3).Build(),
+		Limit:   FloatList().Unit("cores").Build(),
+	}
+
+	got := Dump(&cfg)
+	expected := "timeout = 30 (seconds)\nrate = 2.5 (requests/s)\nsizes = [1 2] (MiB)\n" +
+		"retries = 3\nlimit = <not set>"
+	if got != expected {
+		t.Errorf("Dump() =\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestDumpKV_RoundTrip(t *testing.T) {
+	type Config struct {
+		S   StringParam           `cfg:"s"`
+		I   IntParam              `cfg:"i"`
+		B   BoolParam             `cfg:"b"`
+		F   FloatParam            `cfg:"f"`
+		P   FloatParam            `cfg:"p"`
+		D   DurationParam         `cfg:"d"`
+		T   TimeParam             `cfg:"t"`
+		Dec DecimalParam          `cfg:"dec"`
+		SL  StringListParam       `cfg:"nested.sl"`
+		IL  IntListParam          `cfg:"nested.il"`
+		BL  BoolListParam         `cfg:"nested.bl"`
+		FL  FloatListParam        `cfg:"nested.fl"`
+		DL  DurationListParam     `cfg:"nested.dl"`
+		NL  NestedStringListParam `cfg:"nested.nl"`
+		M   MapStringParam        `cfg:"nested.m"`
+	}
+	newCfg := func() Config {
+		return Config{
+			S:   String().Build(),
+			I:   Int().Build(),
+			B:   Bool().Build(),
+			F:   Float().Build(),
+			P:   Float().Percentage().Build(),
+			D:   Duration().Build(),
+			T:   Time().Build(),
+			Dec: Decimal().Build(),
+			SL:  StringList().Build(),
+			IL:  IntList().Build(),
+			BL:  BoolList().Build(),
+			FL:  FloatList().Build(),
+			DL:  DurationList().Build(),
+			NL:  NestedStringList().Build(),
+			M:   MapString().Build(),
+		}
+	}
+
+	strs := []string{"", "plain", "with, comma", "8080", "true", "null", "a = b", "# not a comment",
+		"line\nbreak", `"quoted" and 'single'`, "ünïcode ✓", "  padded  ", strings.Repeat("long ", 40)}
+	rng := rand.New(rand.NewPCG(1, 2))
+	pick := func() string { return strs[rng.IntN(len(strs))] }
+
+	for i := range 50 {
+		src := newCfg()
+		src.S.value, src.S.set = pick(), true
+		src.I.value, src.I.set = rng.IntN(1<<40)-1<<39, true
+		src.B.value, src.B.set = rng.IntN(2) == 1, true
+		src.F.value, src.F.set = rng.NormFloat64()*1e6, true
+		src.P.value, src.P.set = float64(rng.IntN(101))/100, true
+		src.D.value, src.D.set = time.Duration(rng.Int64N(int64(48*time.Hour))), true
+		src.T.value, src.T.set = time.Unix(rng.Int64N(4e9), 0).UTC(), true
+		if err := src.Dec.setFromString(fmt.Sprintf("%d.%02d", rng.IntN(1e6), rng.IntN(100)), ""); err != nil {
+			t.Fatal(err)
+		}
+		src.SL.value, src.SL.set = []string{}, true
+		for range rng.IntN(4) {
+			src.SL.value = append(src.SL.value, pick())
+		}
+		src.IL.value, src.IL.set = []int{rng.IntN(100), -rng.IntN(100)}, true
+		src.BL.value, src.BL.set = []bool{rng.IntN(2) == 1}, true
+		src.FL.value, src.FL.set = []float64{rng.Float64(), 1e21, 0.1}, true
+		src.DL.value, src.DL.set = []time.Duration{time.Duration(rng.Int64N(1e12)), 0}, true
+		src.NL.value, src.NL.set = [][]string{{pick(), pick()}, {}}, true
+		src.M.value, src.M.set = map[string]string{"env": pick(), "a.b": pick()}, true
+
+		dump, err := DumpKV(&src)
+		if err != nil {
+			t.Fatalf("case %d: DumpKV: %v", i, err)
+		}
+		doc, err := ParseDump(dump)
+		if err != nil {
+			t.Fatalf("case %d: ParseDump: %v\n%s", i, err, dump)
+		}
+		dst := newCfg()
+		if err := Load(&dst, Options{ConfigMap: doc}); err != nil {
+			t.Fatalf("case %d: Load: %v\n%s", i, err, dump)
+		}
+
+		want, got := collectParams(&src, ""), collectParams(&dst, "")
+		for j := range want {
+			if !reflect.DeepEqual(got[j].getAny(), want[j].getAny()) &&
+				got[j].stringValue() != want[j].stringValue() {
+				t.Errorf("case %d: %s = %#v, want %#v\n%s",
+					i, want[j].key(), got[j].getAny(), want[j].getAny(), dump)
+			}
+		}
+		if again, _ := DumpKV(&dst); again != dump {
+			t.Errorf("case %d: dump not stable:\n%s\nvs\n%s", i, again, dump)
+		}
+	}
+}
+
+func TestDumpKV_SkipsSecretsAndUnset(t *testing.T) {
+	type Config struct {
+		Host     StringParam `cfg:"host"`
+		Password StringParam `cfg:"password"`
+		Port     IntParam    `cfg:"port"`
+	}
+	cfg := Config{
+		Host:     String().Default("localhost").Build(),
+		Password: String().Default("s3cret").Secret().Build(),
+		Port:     Int().Build(),
+	}
+	got, err := DumpKV(&cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "host = \"localhost\"\n" {
+		t.Errorf("unexpected dump: %q", got)
+	}
+}
+
+func TestParseDump_Errors(t *testing.T) {
+	tests := map[string]string{
+		"NoEquals":  "host",
+		"BadValue":  "tags = [a, b",
+		"Duplicate": "host = a\nhost = b",
+		"PathTaken": "db = 1\ndb.host = x",
+		"EmptyKey":  " = 1",
+	}
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			var pe *ParseError
+			if _, err := ParseDump(in); !errors.As(err, &pe) {
+				t.Errorf("expected ParseError, got %v", err)
+			}
+		})
+	}
+
+	doc, err := ParseDump("# comment\n\ndb.host = \"x\"\ndb.port = 5432\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	db, ok := doc["db"].(map[string]any)
+	if !ok || db["host"] != "x" || db["port"] != 5432 {
+		t.Errorf("unexpected document: %v", doc)
+	}
+}
+
+func TestDumpEnv(t *testing.T) {
+	type Config struct {
+		Host     StringParam           `cfg:"db.host"`
+		Password StringParam           `cfg:"db.password"`
+		Name     StringParam           `cfg:"db.name"`
+		Timeout  DurationParam         `cfg:"timeout"`
+		Tags     StringListParam       `cfg:"tags"`
+		Routes   NestedStringListParam `cfg:"routes"`
+		Labels   MapStringParam        `cfg:"labels"`
+		Ratio    FloatParam            `cfg:"ratio"`
+		Missing  IntParam              `cfg:"missing"`
+	}
+	cfg := Config{
+		Host:     String().Default("localhost").Build(),
+		Password: String().Default("it's secret").Secret().Build(),
+		Name:     String().Default("my app").Build(),
+		Timeout:  Duration().Default(90 * time.Second).Build(),
+		Tags:     StringList().Default([]string{"a", "b"}).Build(),
+		Routes:   NestedStringList().Default([][]string{{"a", "1"}, {"b", "2"}}).Build(),
+		Labels:   MapString().Default(map[string]string{"team": "ops", "env": "prod"}).Build(),
+		Ratio:    Float().Percentage().Default(25).Build(),
+		Missing:  Int().Build(),
+	}
+
+	expected := `APP_DB_HOST=localhost
+APP_DB_PASSWORD=****
+APP_DB_NAME='my app'
+APP_TIMEOUT=1m30s
+APP_TAGS=a,b
+APP_ROUTES=a:1,b:2
+APP_LABELS=env=prod,team=ops
+APP_RATIO=25
+`
+	if got := DumpEnv(&cfg, "APP"); got != expected {
+		t.Errorf("DumpEnv() =\n%s\nwant:\n%s", got, expected)
+	}
+
+	got := DumpEnvWithSecrets(&cfg, "")
+	if !strings.Contains(got, "DB_PASSWORD='it'\\''s secret'\n") {
+		t.Errorf("expected clear-text secret without prefix, got:\n%s", got)
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	type Config struct {
+		Host     StringParam     `cfg:"db.host"`
+		Port     IntParam        `cfg:"db.port"`
+		Password StringParam     `cfg:"db.password"`
+		Timeout  DurationParam   `cfg:"timeout"`
+		Debug    BoolParam       `cfg:"debug"`
+		Ports    IntListParam    `cfg:"ports"`
+		Tags     StringListParam `cfg:"tags"`
+		Missing  StringParam     `cfg:"missing"`
+	}
+	cfg := Config{
+		Host:     String().Default("localhost").Build(),
+		Port:     Int().Default(5432).Build(),
+		Password: String().Default("hunter2").Secret().Build(),
+		Timeout:  Duration().Default(30 * time.Second).Build(),
+		Debug:    Bool().Default(true).Build(),
+		Ports:    IntList().Default([]int{80, 443}).Build(),
+		Tags:     StringList().Build(),
+		Missing:  String().Build(),
+	}
+
+	got, err := DumpJSON(&cfg)
+	if err != nil {
+		t.Fatalf("DumpJSON() error: %v", err)
+	}
+	expected := `{"db":{"host":"localhost","password":"****","port":5432},"debug":true,` +
+		`"missing":null,"ports":[80,443],"tags":null,"timeout":"30s"}`
+	if string(got) != expected {
+		t.Errorf("DumpJSON() = %s, want %s", got, expected)
+	}
+}
+
+func TestDumpJSON_KeyConflict(t *testing.T) {
+	type Config struct {
+		DB   StringParam `cfg:"db"`
+		Host StringParam `cfg:"db.host"`
+	}
+	cfg := Config{
+		DB:   String().Default("x").Build(),
+		Host: String().Default("y").Build(),
+	}
+	if _, err := DumpJSON(&cfg); !errors.Is(err, errKeyConflict) {
+		t.Errorf("expected errKeyConflict, got %v", err)
+	}
+}
@@ -1,10 +1,16 @@
 package confetto
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
 
+// ErrHelpRequested is returned by Load when Options.AutoHelp is set and the
+// arguments contain -h or --help. The usage text has already been written to
+// Options.HelpWriter, so callers usually just exit with status 0.
+var ErrHelpRequested = errors.New("help requested")
+
 // LoadError contains all errors that occurred during configuration loading.
 type LoadError struct {
 	Errors []error
@@ -53,22 +59,196 @@ func (e *ParseError) Unwrap() error {
 	return e.Err
 }
 
+// maskSecretError hides the value in a ParseError for the secret param p,
+// so that err.Error() never shows the secret: Value becomes "****" and the
+// text of Err, which may quote the input, has it masked too. Err remains
+// available to errors.Is and errors.As.
+func maskSecretError(p Param, err error) error {
+	var pe *ParseError
+	if !p.isSecret() || !errors.As(err, &pe) {
+		return err
+	}
+	if pe.Err != nil {
+		pe.Err = &maskedError{err: pe.Err, value: pe.Value}
+	}
+	pe.Value = maskedValue
+	return err
+}
+
+// errorValue returns the value of p to report in an error: "****" for a
+// secret.
+func errorValue(p Param) any {
+	if p.isSecret() {
+		return maskedValue
+	}
+	return p.getAny()
+}
+
+// errorString returns the value of p as a string to report in an error
+// message: "****" for a secret.
+func errorString(p Param) string {
+	if p.isSecret() {
+		return maskedValue
+	}
+	return p.stringValue()
+}
+
+// maskedError hides a secret value in the text of err.
+type maskedError struct {
+	err   error
+	value string
+}
+
+func (e *maskedError) Error() string {
+	return maskValue(e.err.Error(), e.value)
+}
+
+func (e *maskedError) Unwrap() error {
+	return e.err
+}
+
+// maskValue replaces each occurrence of value in s with "****".
+func maskValue(s, value string) string {
+	if value == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, value, maskedValue)
+}
+
 // ValidationError indicates that a value failed validation.
 type ValidationError struct {
 	Key     string
 	Value   any
 	Message string
+	// Rule is the name of the failed Loader.Rule, if any. Key and Value are
+	// empty for rules.
+	Rule string
 }
 
 func (e *ValidationError) Error() string {
+	if e.Rule != "" {
+		return fmt.Sprintf("rule %q failed: %s", e.Rule, e.Message)
+	}
 	return fmt.Sprintf("validation failed for %q (value: %v): %s", e.Key, e.Value, e.Message)
 }
 
-// RequiredError indicates that a required parameter was not set.
+// RequiredError indicates that a required parameter was not set. EnvVar and
+// Flag, when known, name the environment variable and CLI flag that would
+// set it. Profile is the active profile if the parameter is only required
+// in some profiles.
 type RequiredError struct {
-	Key string
+	Key     string
+	EnvVar  string
+	Flag    string
+	Profile string
+	// Condition explains a conditional requirement, such as "tls.enabled"
+	// for RequireIf.
+	Condition string
 }
 
 func (e *RequiredError) Error() string {
-	return fmt.Sprintf("required parameter %q is not set", e.Key)
+	msg := fmt.Sprintf("required parameter %q is not set", e.Key)
+	if e.Profile != "" {
+		msg += fmt.Sprintf(" in profile %q", e.Profile)
+	}
+	if e.Condition != "" {
+		msg += " when " + e.Condition
+	}
+	if e.EnvVar != "" && e.Flag != "" {
+		msg += fmt.Sprintf(" (set %s or %s)", e.EnvVar, e.Flag)
+	}
+	return msg
+}
+
+// MissingEnvError indicates that an environment variable expected for a key
+// was not set, such as an index below a list count variable.
+type MissingEnvError struct {
+	Key string
+	Var string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("environment variable %q for key %q is not set", e.Var, e.Key)
+}
+
+// PositionalArgsError indicates that non-flag arguments were passed while
+// Options.DisallowPositionals is set.
+type PositionalArgsError struct {
+	Args []string
+}
+
+func (e *PositionalArgsError) Error() string {
+	return fmt.Sprintf("unexpected positional arguments: %q", e.Args)
+}
+
+// UnknownKeyError indicates that a key referenced by a Loader check does not
+// match any registered parameter.
+type UnknownKeyError struct {
+	Key string
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("unknown parameter %q", e.Key)
+}
+
+// DeniedSourceError indicates that a value came from a source the parameter
+// rejects with RejectSource.
+type DeniedSourceError struct {
+	Key    string
+	Source string
+}
+
+func (e *DeniedSourceError) Error() string {
+	return fmt.Sprintf("parameter %q cannot be set from %s", e.Key, e.Source)
+}
+
+// Warning describes a non-fatal condition encountered during loading.
+type Warning struct {
+	Key     string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%q: %s", w.Key, w.Message)
+}
+
+// ExprError indicates that an expression passed to Loader.RequireExpr could
+// not be parsed.
+type ExprError struct {
+	Expr    string
+	Message string
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("invalid expression %q: %s", e.Expr, e.Message)
+}
+
+// PrefixConflictError indicates that a config registered with
+// Loader.RegisterNamed has the same prefix as other registered configs.
+// Types lists the types of all of them, in registration order.
+type PrefixConflictError struct {
+	Prefix string
+	Types  []string
+}
+
+func (e *PrefixConflictError) Error() string {
+	return fmt.Sprintf(
+		"prefix %q is registered by %d configs: %s",
+		e.Prefix, len(e.Types), strings.Join(e.Types, ", "),
+	)
+}
+
+// DuplicateKeyError indicates that several parameters of the registered
+// configs have the same key. Types lists the type of the config defining
+// each of them, in registration order.
+type DuplicateKeyError struct {
+	Key   string
+	Types []string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf(
+		"key %q is defined by %d parameters, in %s",
+		e.Key, len(e.Types), strings.Join(e.Types, ", "),
+	)
 }
@@ -3,6 +3,7 @@ package confetto
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -61,6 +62,12 @@ func TestLoad_ErrorMessages(t *testing.T) {
 		if !strings.Contains(msg, "db.host") {
 			t.Errorf("expected key in message, got %q", msg)
 		}
+
+		re = &RequiredError{Key: "db.password", EnvVar: "MYAPP_DB_PASSWORD", Flag: "--db.password"}
+		expected := `required parameter "db.password" is not set (set MYAPP_DB_PASSWORD or --db.password)`
+		if msg := re.Error(); msg != expected {
+			t.Errorf("expected %q, got %q", expected, msg)
+		}
 	})
 
 	t.Run("ValidationError", func(t *testing.T) {
@@ -71,3 +78,92 @@ func TestLoad_ErrorMessages(t *testing.T) {
 		}
 	})
 }
+
+func TestLoad_SecretValuesMaskedInErrors(t *testing.T) {
+	type config struct {
+		Token StringParam `cfg:"token"`
+		PIN   IntParam    `cfg:"pin"`
+	}
+	cfg := config{
+		Token: String().Secret().Validate(OneOf("a", "b")).Build(),
+		PIN:   Int().Secret().Build(),
+	}
+	err := Load(&cfg, Options{Args: []string{"--token=hunter2-token", "--pin=hunter2-pin"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected LoadError with 2 errors, got %v", err)
+	}
+	if msg := err.Error(); strings.Contains(msg, "hunter2") {
+		t.Errorf("secret leaked in error message: %q", msg)
+	}
+
+	var parseErr *ParseError
+	if !errors.As(loadErr.Errors[0], &parseErr) || parseErr.Value != maskedValue {
+		t.Fatalf("expected masked ParseError, got %#v", loadErr.Errors[0])
+	}
+	var valErr *ValidationError
+	if !errors.As(loadErr.Errors[1], &valErr) || valErr.Value != maskedValue {
+		t.Errorf("expected masked ValidationError, got %#v", loadErr.Errors[1])
+	}
+	if !errors.Is(parseErr, strconv.ErrSyntax) {
+		t.Errorf("expected the masked error to still wrap strconv.ErrSyntax")
+	}
+}
+
+func TestLoad_SecretValuesMaskedInInterpolationAndChecks(t *testing.T) {
+	type config struct {
+		Pass    StringParam    `cfg:"pass"`
+		Pool    StringParam    `cfg:"pool"`
+		Backend StringParam    `cfg:"backend"`
+		Quota   IntParam       `cfg:"quota"`
+		Pools   IntParam       `cfg:"pools.small.size"`
+		Map     MapStringParam `cfg:"backends"`
+	}
+	tests := []struct {
+		name  string
+		args  []string
+		check func(l *Loader)
+	}{
+		{"UnterminatedReference", []string{"--pass=hunter2${oops"}, nil},
+		{"Cycle", []string{"--pass=hunter2${pool}", "--pool=${pass}"}, nil},
+		{"RequireReferences", []string{"--pool=hunter2"}, func(l *Loader) {
+			l.RequireReferences("pool", "pools")
+		}},
+		{"RequireKeyOf", []string{"--backend=hunter2"}, func(l *Loader) {
+			l.RequireKeyOf("backend", "backends")
+		}},
+		{"RequireSumAtMost", []string{"--quota=777111"}, func(l *Loader) {
+			l.RequireSumAtMost(10, "quota", "pools.small.size")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config{
+				Pass:    String().Secret().Build(),
+				Pool:    String().Secret().Build(),
+				Backend: String().Secret().Build(),
+				Quota:   Int().Secret().Build(),
+				Pools:   Int().Default(1).Build(),
+				Map:     MapString().Default(map[string]string{"a": "b"}).Build(),
+			}
+			l := NewLoader(Options{Args: tt.args, Interpolate: true})
+			l.Register("", &cfg)
+			if tt.check != nil {
+				tt.check(l)
+			}
+			err := l.Load()
+			var loadErr *LoadError
+			if !errors.As(err, &loadErr) {
+				t.Fatalf("expected LoadError, got %v", err)
+			}
+			var valErr *ValidationError
+			if !errors.As(loadErr.Errors[0], &valErr) || valErr.Value != maskedValue {
+				t.Errorf("expected masked ValidationError, got %#v", loadErr.Errors[0])
+			}
+			if msg := err.Error(); strings.Contains(msg, "hunter2") ||
+				strings.Contains(msg, "777") {
+				t.Errorf("secret leaked in error message: %q", msg)
+			}
+		})
+	}
+}
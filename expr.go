@@ -0,0 +1,306 @@
+package confetto
+
+import (
+	"strings"
+)
+
+// RequireExpr requires a boolean expression over parameter keys to hold once
+// all parameters have been loaded, e.g. "tls.enabled -> tls.cert && tls.key".
+//
+// The grammar, from lowest to highest precedence, is implication (a -> b),
+// or (a || b), and (a && b), negation (!a), and comparison (a == b,
+// a != b), with parentheses for grouping. Operands are keys, quoted strings
+// ('x' or "x"), numbers, true and false. Comparisons use the formatted
+// value, so "port == 8080" and "mode == 'prod'" both work; a key with
+// neither a value nor a default formats as an empty string. Used as a
+// condition, a bool key stands for its value and any other key for whether
+// it has a value or a default.
+//
+// An invalid expression is reported as an ExprError by Load.
+func (l *Loader) RequireExpr(expr string) {
+	node, err := parseExpr(expr)
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		if err != nil {
+			return err
+		}
+		v, err := node.eval(params)
+		if err != nil {
+			return err
+		}
+		if !v.truth {
+			return &ValidationError{Key: expr, Value: false, Message: "expression does not hold"}
+		}
+		return nil
+	})
+}
+
+// exprValue is the result of evaluating an expression node: its formatted
+// text for comparisons and its truth for conditions.
+type exprValue struct {
+	text  string
+	truth bool
+}
+
+func boolValue(b bool) exprValue {
+	if b {
+		return exprValue{text: "true", truth: true}
+	}
+	return exprValue{text: "false"}
+}
+
+type exprNode interface {
+	eval(params map[string]Param) (exprValue, error)
+}
+
+// exprLiteral is a quoted string, number, true or false.
+type exprLiteral struct {
+	value exprValue
+}
+
+func (n exprLiteral) eval(map[string]Param) (exprValue, error) {
+	return n.value, nil
+}
+
+// exprKey is a reference to a parameter.
+type exprKey struct {
+	key string
+}
+
+func (n exprKey) eval(params map[string]Param) (exprValue, error) {
+	p, ok := params[n.key]
+	if !ok {
+		return exprValue{}, &UnknownKeyError{Key: n.key}
+	}
+	if !p.IsSet() && !p.hasDefault() {
+		return exprValue{}, nil
+	}
+	if b, ok := p.getAny().(bool); ok {
+		return boolValue(b), nil
+	}
+	return exprValue{text: p.stringValue(), truth: true}, nil
+}
+
+// exprNot negates its operand.
+type exprNot struct {
+	x exprNode
+}
+
+func (n exprNot) eval(params map[string]Param) (exprValue, error) {
+	v, err := n.x.eval(params)
+	if err != nil {
+		return exprValue{}, err
+	}
+	return boolValue(!v.truth), nil
+}
+
+// exprBinary applies a binary operator.
+type exprBinary struct {
+	op   string
+	x, y exprNode
+}
+
+func (n exprBinary) eval(params map[string]Param) (exprValue, error) {
+	x, err := n.x.eval(params)
+	if err != nil {
+		return exprValue{}, err
+	}
+	// short-circuit so the right side may reference keys that only exist
+	// in some setups
+	switch {
+	case n.op == "&&" && !x.truth, n.op == "->" && !x.truth:
+		return boolValue(n.op == "->"), nil
+	case n.op == "||" && x.truth:
+		return boolValue(true), nil
+	}
+	y, err := n.y.eval(params)
+	if err != nil {
+		return exprValue{}, err
+	}
+	switch n.op {
+	case "==":
+		return boolValue(x.text == y.text), nil
+	case "!=":
+		return boolValue(x.text != y.text), nil
+	default: // &&, || and -> all reduce to the right side here
+		return boolValue(y.truth), nil
+	}
+}
+
+// exprParser is a recursive descent parser over the tokens of an expression.
+type exprParser struct {
+	expr string
+	toks []string
+	pos  int
+}
+
+func parseExpr(expr string) (exprNode, error) {
+	toks, err := tokenizeExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{expr: expr, toks: toks}
+	node, err := p.implication()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, p.errorf("unexpected " + p.toks[p.pos])
+	}
+	return node, nil
+}
+
+func (p *exprParser) errorf(msg string) error {
+	return &ExprError{Expr: p.expr, Message: msg}
+}
+
+// accept consumes the next token if it is tok.
+func (p *exprParser) accept(tok string) bool {
+	if p.pos < len(p.toks) && p.toks[p.pos] == tok {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// implication is right-associative: a -> b -> c is a -> (b -> c).
+func (p *exprParser) implication() (exprNode, error) {
+	x, err := p.or()
+	if err != nil || !p.accept("->") {
+		return x, err
+	}
+	y, err := p.implication()
+	if err != nil {
+		return nil, err
+	}
+	return exprBinary{op: "->", x: x, y: y}, nil
+}
+
+func (p *exprParser) or() (exprNode, error) {
+	return p.leftAssoc("||", p.and)
+}
+
+func (p *exprParser) and() (exprNode, error) {
+	return p.leftAssoc("&&", p.unary)
+}
+
+func (p *exprParser) leftAssoc(op string, next func() (exprNode, error)) (exprNode, error) {
+	x, err := next()
+	for err == nil && p.accept(op) {
+		var y exprNode
+		if y, err = next(); err == nil {
+			x = exprBinary{op: op, x: x, y: y}
+		}
+	}
+	return x, err
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return exprNot{x: x}, nil
+	}
+	return p.comparison()
+}
+
+func (p *exprParser) comparison() (exprNode, error) {
+	x, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!="} {
+		if p.accept(op) {
+			y, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return exprBinary{op: op, x: x, y: y}, nil
+		}
+	}
+	return x, nil
+}
+
+func (p *exprParser) operand() (exprNode, error) {
+	if p.pos == len(p.toks) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+	switch {
+	case tok == "(":
+		x, err := p.implication()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("missing )")
+		}
+		return x, nil
+	case tok == "true" || tok == "false":
+		return exprLiteral{value: boolValue(tok == "true")}, nil
+	case tok[0] == '\'' || tok[0] == '"':
+		text := tok[1 : len(tok)-1]
+		return exprLiteral{value: exprValue{text: text, truth: text != ""}}, nil
+	case isExprNumber(tok[0]):
+		return exprLiteral{value: exprValue{text: tok, truth: true}}, nil
+	case isExprKey(tok[0]):
+		return exprKey{key: tok}, nil
+	default:
+		return nil, p.errorf("unexpected " + tok)
+	}
+}
+
+// tokenizeExpr splits an expression into operators, parentheses, quoted
+// strings, numbers and keys.
+func tokenizeExpr(expr string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')':
+			toks = append(toks, string(c))
+			i++
+		case strings.HasPrefix(expr[i:], "->"), strings.HasPrefix(expr[i:], "&&"),
+			strings.HasPrefix(expr[i:], "||"), strings.HasPrefix(expr[i:], "=="),
+			strings.HasPrefix(expr[i:], "!="):
+			toks = append(toks, expr[i:i+2])
+			i += 2
+		case c == '!':
+			toks = append(toks, "!")
+			i++
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, &ExprError{Expr: expr, Message: "unterminated string"}
+			}
+			toks = append(toks, expr[i:i+end+2])
+			i += end + 2
+		case isExprKey(c) || isExprNumber(c):
+			j := i
+			for j < len(expr) && (isExprKey(expr[j]) || isExprNumber(expr[j]) ||
+				expr[j] == '-' && !strings.HasPrefix(expr[j:], "->")) {
+				j++
+			}
+			toks = append(toks, expr[i:j])
+			i = j
+		default:
+			return nil, &ExprError{Expr: expr, Message: "unexpected character " + string(c)}
+		}
+	}
+	return toks, nil
+}
+
+// isExprKey reports whether c can start a key. After the first character,
+// keys may also contain digits and dashes.
+func isExprKey(c byte) bool {
+	return c == '_' || c == '.' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// isExprNumber reports whether c can start a number.
+func isExprNumber(c byte) bool {
+	return '0' <= c && c <= '9'
+}
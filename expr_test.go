@@ -0,0 +1,92 @@
+package confetto
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoader_RequireExpr(t *testing.T) {
+	type tlsConfig struct {
+		Enabled BoolParam   `cfg:"enabled"`
+		Cert    StringParam `cfg:"cert"`
+		Key     StringParam `cfg:"key"`
+	}
+	type config struct {
+		TLS  tlsConfig   `cfg:"tls"`
+		Mode StringParam `cfg:"mode"`
+		Port IntParam    `cfg:"port"`
+	}
+
+	load := func(expr string, args ...string) error {
+		cfg := config{
+			TLS: tlsConfig{
+				Enabled: Bool().Default(false).Build(),
+				Cert:    String().Build(),
+				Key:     String().Build(),
+			},
+			Mode: String().Default("dev").Build(),
+			Port: Int().Default(8080).Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		l.RequireExpr(expr)
+		err := l.Load()
+		var loadErr *LoadError
+		if errors.As(err, &loadErr) {
+			return loadErr.Errors[0]
+		}
+		return err
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		args []string
+		ok   bool
+	}{
+		{"ImplicationOff", "tls.enabled -> tls.cert != ''", nil, true},
+		{"ImplicationHolds", "tls.enabled -> tls.cert != ''",
+			[]string{"--tls.enabled=true", "--tls.cert=c.pem"}, true},
+		{"ImplicationFails", "tls.enabled -> tls.cert != ''",
+			[]string{"--tls.enabled=true"}, false},
+		{"IsSet", "tls.enabled -> (tls.cert && tls.key)",
+			[]string{"--tls.enabled", "--tls.cert=c.pem"}, false},
+		{"OrAnd", "(mode == 'prod' && tls.enabled) || mode != \"prod\"", nil, true},
+		{"OrAndFails", "(mode == 'prod' && tls.enabled) || mode != \"prod\"",
+			[]string{"--mode=prod"}, false},
+		{"Number", "port == 8080 && !tls.cert", nil, true},
+		{"Not", "!(port == 9090)", []string{"--port=9090"}, false},
+		{"RightAssoc", "false -> false -> false", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := load(tt.expr, tt.args...)
+			if tt.ok {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var ve *ValidationError
+			if !errors.As(err, &ve) || ve.Key != tt.expr {
+				t.Errorf("expected ValidationError for %q, got %v", tt.expr, err)
+			}
+		})
+	}
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		var uk *UnknownKeyError
+		if err := load("tls.enabled || missing"); !errors.As(err, &uk) || uk.Key != "missing" {
+			t.Errorf("expected UnknownKeyError, got %v", err)
+		}
+	})
+
+	for _, bad := range []string{"mode ==", "(mode", "mode 'x'", "mode = 'x'", "'open"} {
+		t.Run("Invalid "+bad, func(t *testing.T) {
+			var ee *ExprError
+			if err := load(bad); !errors.As(err, &ee) {
+				t.Errorf("expected ExprError, got %v", err)
+			}
+		})
+	}
+}
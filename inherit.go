@@ -0,0 +1,95 @@
+package confetto
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// inheritor resolves Inherit defaults, following chains of inheritance and
+// detecting cycles.
+type inheritor struct {
+	params map[string]Param
+	// visiting holds the keys being resolved, in order, to report cycles.
+	visiting []string
+	done     map[string]error
+}
+
+// inheritDefaults gives each param that inherits and is not set the value of
+// its parent, if the parent has one, and returns an error for each param
+// that could not inherit.
+func inheritDefaults(params []Param) []error {
+	in := &inheritor{
+		params: make(map[string]Param, len(params)),
+		done:   make(map[string]error),
+	}
+	for _, p := range params {
+		in.params[p.key()] = p
+	}
+
+	var errs []error
+	for _, p := range params {
+		if p.parent() == "" {
+			continue
+		}
+		if _, ok := in.done[p.key()]; ok {
+			continue
+		}
+		// a failing parent fails its whole chain, so report it once
+		if err := in.resolve(p); err != nil && !slices.Contains(errs, err) {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// resolve sets the inherited default of p, resolving its parent first.
+func (in *inheritor) resolve(p Param) error {
+	key := p.key()
+	if err, ok := in.done[key]; ok {
+		return err
+	}
+	for i, k := range in.visiting {
+		if k == key {
+			cycle := append(slices.Clone(in.visiting[i:]), key)
+			return &ValidationError{
+				Key:     cycle[0],
+				Value:   p.parent(),
+				Message: "inheritance cycle: " + strings.Join(cycle, " -> "),
+			}
+		}
+	}
+
+	in.visiting = append(in.visiting, key)
+	err := in.inherit(p)
+	in.visiting = in.visiting[:len(in.visiting)-1]
+	in.done[key] = err
+	return err
+}
+
+func (in *inheritor) inherit(p Param) error {
+	parent, ok := in.params[p.parent()]
+	if !ok {
+		return &ValidationError{
+			Key:     p.key(),
+			Value:   p.parent(),
+			Message: fmt.Sprintf("inherits from unknown key %q", p.parent()),
+		}
+	}
+	if parent.parent() != "" {
+		if err := in.resolve(parent); err != nil {
+			return err
+		}
+	}
+	if p.IsSet() || (!parent.IsSet() && !parent.hasDefault()) {
+		return nil
+	}
+	if !p.inherit(parent) {
+		return &ValidationError{
+			Key:     p.key(),
+			Value:   p.parent(),
+			Message: fmt.Sprintf("cannot inherit from %q: different type", p.parent()),
+		}
+	}
+	return nil
+}
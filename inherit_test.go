@@ -0,0 +1,140 @@
+package confetto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad_Inherit(t *testing.T) {
+	type Endpoint struct {
+		Timeout DurationParam `cfg:"timeout"`
+		Retries IntParam      `cfg:"retries"`
+	}
+	type Config struct {
+		Timeout DurationParam `cfg:"timeout"`
+		Retries IntParam      `cfg:"retries"`
+		Users   Endpoint      `cfg:"users"`
+		Orders  Endpoint      `cfg:"orders"`
+		Audit   Endpoint      `cfg:"audit"`
+	}
+
+	cfg := Config{
+		Timeout: Duration().Default(5 * time.Second).Build(),
+		Retries: Int().Build(),
+		Users: Endpoint{
+			Timeout: Duration().Inherit("timeout").Build(),
+			Retries: Int().Default(1).Inherit("retries").Build(),
+		},
+		Orders: Endpoint{
+			Timeout: Duration().Inherit("timeout").Build(),
+			Retries: Int().Inherit("retries").Build(),
+		},
+		Audit: Endpoint{
+			Timeout: Duration().Inherit("orders.timeout").Build(),
+			Retries: Int().Build(),
+		},
+	}
+	err := Load(&cfg, Options{Args: []string{"--timeout=10s", "--orders.timeout=30s"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := cfg.Users.Timeout.Get(); got != 10*time.Second {
+		t.Errorf("expected users.timeout inherited as 10s, got %v", got)
+	}
+	if got := cfg.Orders.Timeout.Get(); got != 30*time.Second {
+		t.Errorf("expected orders.timeout override 30s, got %v", got)
+	}
+	if got := cfg.Audit.Timeout.Get(); got != 30*time.Second {
+		t.Errorf("expected audit.timeout inherited through orders as 30s, got %v", got)
+	}
+	if got := cfg.Users.Retries.Get(); got != 1 {
+		t.Errorf("expected own default when parent is unset, got %d", got)
+	}
+	if cfg.Users.Timeout.IsSet() {
+		t.Error("expected inherited value to count as a default, not as set")
+	}
+}
+
+func TestLoader_InheritReload(t *testing.T) {
+	type Config struct {
+		Timeout DurationParam `cfg:"timeout"`
+		Child   DurationParam `cfg:"child.timeout"`
+		Other   DurationParam `cfg:"other.timeout"`
+	}
+	cfg := Config{
+		Timeout: Duration().Build(),
+		Child:   Duration().Default(time.Second).Inherit("timeout").Build(),
+		Other:   Duration().Inherit("timeout").Build(),
+	}
+	l := NewLoader(Options{Args: []string{"--timeout=10s"}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Child.Get() != 10*time.Second || cfg.Other.Get() != 10*time.Second {
+		t.Fatalf("expected 10s inherited, got %v and %v", cfg.Child.Get(), cfg.Other.Get())
+	}
+	if usage := l.Usage(); !strings.Contains(usage, "(default 1s)") ||
+		strings.Contains(usage, "(default 10s)") {
+		t.Errorf("expected only the declared default in usage, got:\n%s", usage)
+	}
+
+	l.opts.Args = nil
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Child.Get() != time.Second {
+		t.Errorf("expected child back to its own default, got %v", cfg.Child.Get())
+	}
+	if cfg.Other.Get() != 0 || cfg.Other.hasDefault() {
+		t.Errorf("expected other.timeout to have no value, got %v", cfg.Other.Get())
+	}
+}
+
+func TestLoad_InheritErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Param
+		want string
+	}{
+		{
+			name: "Cycle",
+			a:    ptr(Int().Inherit("b").Build()),
+			b:    ptr(Int().Default(1).Inherit("a").Build()),
+			want: "inheritance cycle",
+		},
+		{
+			name: "UnknownKey",
+			a:    ptr(Int().Inherit("missing").Build()),
+			b:    ptr(Int().Inherit("a").Build()),
+			want: "unknown key",
+		},
+		{
+			name: "DifferentType",
+			a:    ptr(Int().Inherit("b").Build()),
+			b:    ptr(String().Default("x").Build()),
+			want: "different type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.a.setKey("a")
+			tt.b.setKey("b")
+			errs := inheritDefaults([]Param{tt.a, tt.b})
+			if len(errs) != 1 {
+				t.Fatalf("expected one error, got %v", errs)
+			}
+			var ve *ValidationError
+			if !errors.As(errs[0], &ve) || !strings.Contains(ve.Message, tt.want) {
+				t.Errorf("expected error mentioning %q, got %v", tt.want, errs[0])
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,135 @@
+package confetto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// interpolator expands ${key} references between string params, following
+// references transitively and detecting cycles.
+type interpolator struct {
+	params map[string]Param
+	// visiting holds the keys being expanded, in order, to report cycles.
+	visiting []string
+	done     map[string]error
+}
+
+// interpolate expands ${key} references in the string params with the
+// current values of the referenced params and returns an error for each
+// param that could not be expanded. Expanded values keep their origin and
+// set state, so defaults can be interpolated too.
+func interpolate(params []Param) []error {
+	in := &interpolator{
+		params: make(map[string]Param, len(params)),
+		done:   make(map[string]error),
+	}
+	for _, p := range params {
+		in.params[p.key()] = p
+	}
+
+	var errs []error
+	for _, p := range params {
+		sp, ok := p.(*StringParam)
+		if !ok {
+			continue
+		}
+		if _, ok := in.done[sp.k]; ok {
+			continue
+		}
+		if err := in.expand(sp); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// expand replaces the references in the value of p, expanding referenced
+// string params first.
+func (in *interpolator) expand(p *StringParam) error {
+	if err, ok := in.done[p.k]; ok {
+		return err
+	}
+	for i, k := range in.visiting {
+		if k == p.k {
+			cycle := append(slices.Clone(in.visiting[i:]), p.k)
+			return &ValidationError{
+				Key:     cycle[0],
+				Value:   errorValue(p),
+				Message: "interpolation cycle: " + strings.Join(cycle, " -> "),
+			}
+		}
+	}
+
+	in.visiting = append(in.visiting, p.k)
+	v, err := expandRefs(p.k, p.value, in.lookup)
+	in.visiting = in.visiting[:len(in.visiting)-1]
+	var ve *ValidationError
+	switch {
+	case err == nil:
+		p.value = v
+	case p.isSecret() && errors.As(err, &ve) && ve.Key == p.k:
+		ve.Value = maskedValue
+	}
+	in.done[p.k] = err
+	return err
+}
+
+// lookup returns the final value of the param referenced by key from.
+func (in *interpolator) lookup(from, ref string) (string, error) {
+	p, ok := in.params[ref]
+	if !ok {
+		return "", &ValidationError{
+			Key:     from,
+			Value:   "${" + ref + "}",
+			Message: fmt.Sprintf("references unknown key %q", ref),
+		}
+	}
+	if sp, ok := p.(*StringParam); ok {
+		if err := in.expand(sp); err != nil {
+			return "", err
+		}
+	}
+	return p.stringValue(), nil
+}
+
+// expandRefs replaces each ${ref} in s with the value returned by lookup and
+// each "$$" with "$". Any other "$" is kept as is.
+func expandRefs(
+	key, s string, lookup func(from, ref string) (string, error),
+) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", &ValidationError{
+					Key:     key,
+					Value:   s,
+					Message: "unterminated ${ reference",
+				}
+			}
+			v, err := lookup(key, s[i+2:i+2+end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i += 2 + end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
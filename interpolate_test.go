@@ -0,0 +1,89 @@
+package confetto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoad_Interpolate(t *testing.T) {
+	type config struct {
+		Scheme  StringParam `cfg:"scheme"`
+		Host    StringParam `cfg:"host"`
+		Port    IntParam    `cfg:"port"`
+		BaseURL StringParam `cfg:"base_url"`
+		Health  StringParam `cfg:"health_url"`
+		Price   StringParam `cfg:"price"`
+	}
+
+	cfg := config{
+		Scheme:  String().Default("https").Build(),
+		Host:    String().Build(),
+		Port:    Int().Default(8443).Build(),
+		Health:  String().Default("${base_url}/healthz").Validate(MinLen(30)).Build(),
+		BaseURL: String().Build(),
+		Price:   String().Build(),
+	}
+	err := Load(&cfg, Options{
+		Args: []string{
+			"--host=example.com",
+			"--base_url=${scheme}://${host}:${port}",
+			"--price=$$5 or $10",
+		},
+		Interpolate: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.BaseURL.Get(); got != "https://example.com:8443" {
+		t.Errorf("unexpected base_url: %q", got)
+	}
+	if got := cfg.Health.Get(); got != "https://example.com:8443/healthz" {
+		t.Errorf("unexpected health_url: %q", got)
+	}
+	if cfg.Health.IsSet() {
+		t.Error("expected interpolated default to stay unset")
+	}
+	if got := cfg.Price.Get(); got != "$5 or $10" {
+		t.Errorf("unexpected price: %q", got)
+	}
+}
+
+func TestLoad_InterpolateDisabled(t *testing.T) {
+	type config struct {
+		BaseURL StringParam `cfg:"base_url"`
+	}
+	cfg := config{BaseURL: String().Build()}
+	if err := Load(&cfg, Options{Args: []string{"--base_url=${host}"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.BaseURL.Get(); got != "${host}" {
+		t.Errorf("expected value to be kept verbatim, got %q", got)
+	}
+}
+
+func TestLoad_InterpolateErrors(t *testing.T) {
+	type config struct {
+		A StringParam `cfg:"a"`
+		B StringParam `cfg:"b"`
+		C StringParam `cfg:"c"`
+	}
+	cfg := config{A: String().Build(), B: String().Build(), C: String().Build()}
+	err := Load(&cfg, Options{
+		Args:        []string{"--a=${b}", "--b=x${a}", "--c=${missing}"},
+		Interpolate: true,
+	})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	if len(loadErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", loadErr.Errors)
+	}
+	if !strings.Contains(loadErr.Errors[0].Error(), "a -> b -> a") {
+		t.Errorf("expected cycle error, got %v", loadErr.Errors[0])
+	}
+	if !strings.Contains(loadErr.Errors[1].Error(), `unknown key "missing"`) {
+		t.Errorf("expected unknown key error, got %v", loadErr.Errors[1])
+	}
+}
@@ -1,9 +1,17 @@
 package confetto
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"net"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Options configures the configuration loader.
@@ -14,27 +22,131 @@ type Options struct {
 	// ConfigPaths is a list of paths to search for the config file.
 	// The first existing file is used. Ignored if ConfigFile is set.
 	ConfigPaths []string
+	// MergeConfigFiles are YAML files that are all read, in order, and
+	// deep-merged into a single config file, so later files override
+	// earlier ones key by key and nested maps are merged rather than
+	// replaced. Missing files are skipped. If set, ConfigFile and
+	// ConfigPaths are ignored, and RelativeToConfig paths and RawConfig
+	// refer to the last file read.
+	MergeConfigFiles []string
+	// ExpandEnvInYAML expands ${VAR} and $VAR in the string values of the
+	// config file with environment variables, unset ones expanding to the
+	// empty string. "$$" stands for a literal "$". Other scalars and keys
+	// are left as they are.
+	ExpandEnvInYAML bool
+	// WatchInterval is how often Loader.Watch checks the config file for
+	// changes (default: one second).
+	WatchInterval time.Duration
+	// SecretRotated, if set, makes Loader.Watch also poll the files read
+	// through EnvFileSuffix. When one changes, only the parameter read from
+	// it is read again, checked and updated, and SecretRotated is called
+	// with its key, without reloading the other parameters.
+	SecretRotated func(key string)
+	// ConfigMap is an already decoded document with the same structure as
+	// the YAML file. It is consulted after the file and ConfigDir, so values
+	// in those take precedence.
+	ConfigMap map[string]any
+	// ConfigDir is a directory tree read as configuration, such as a mounted
+	// Kubernetes ConfigMap: file names are keys, file contents are values and
+	// nested directories add key segments (db/host is read as db.host). It is
+	// consulted after the YAML file and before ConfigMap.
+	ConfigDir string
 	// EnvPrefix is the prefix for environment variables.
 	EnvPrefix string
+	// DotEnvFile is a .env file of KEY=value lines read right after the
+	// environment, so real environment variables take precedence. Names
+	// are matched like environment variables, prefixes included. A missing
+	// file is ignored.
+	DotEnvFile string
+	// EnvPrefixes are further prefixes for environment variables, tried in
+	// order after EnvPrefix, so with EnvPrefix "MYAPP" and EnvPrefixes
+	// ["PLATFORM"] MYAPP_DB_HOST wins over PLATFORM_DB_HOST. Every form
+	// under a prefix, including file variables and indexed lists, wins over
+	// all forms under the following ones.
+	EnvPrefixes []string
 	// Args are the command line arguments to parse.
 	Args []string
+	// DisallowPositionals makes Load fail if Args contains non-flag tokens
+	// before the "--" terminator, which usually are flags missing their
+	// leading dashes.
+	DisallowPositionals bool
+	// AutoHelp makes Load write the Usage text to HelpWriter and return
+	// ErrHelpRequested, without loading anything, if Args contains -h or
+	// --help before the "--" terminator.
+	AutoHelp bool
+	// HelpWriter receives the usage text for AutoHelp (default: os.Stderr).
+	HelpWriter io.Writer
+	// Profile is the active deployment profile, such as "dev" or "prod".
+	// Params built with RequiredInProfile are required only in their
+	// profiles.
+	Profile string
+	// SourceOrder is the priority of the main sources, highest first
+	// (default: SourceCLI, SourceEnv, SourceFile). Kinds left out are not
+	// consulted. ConfigDir and ConfigMap are always consulted after them.
+	SourceOrder []SourceKind
+	// SourceLabels renames sources in provenance: the SourceName of
+	// LoadReport, deprecation warnings, DeniedSourceError and debug logs.
+	// Keys are built-in source names ("cli", "env", "dotenv", "yaml",
+	// "dir", "map") or the path of a config file as read, which for
+	// MergeConfigFiles labels the keys whose value came from that file,
+	// e.g. {"config.prod.yaml": "override-config", "env": "cluster-env"}.
+	SourceLabels map[string]string
+	// UnwrapSingleItemLists lets a single-value param take the item of a
+	// one-item YAML list, with a warning, instead of failing.
+	UnwrapSingleItemLists bool
+	// WrapScalarsInLists lets a list param take a single non-string YAML
+	// value as a one-item list instead of failing. Strings are always split
+	// by ListSeparator.
+	WrapScalarsInLists bool
 	// ListSeparator is the separator for list values in strings (default: ",").
 	ListSeparator string
+	// EnvListCountSuffix enables reading list params from indexed env vars
+	// bounded by a count variable: with "_COUNT", PREFIX_NODES_COUNT=2 reads
+	// PREFIX_NODES_0 and PREFIX_NODES_1. Empty disables it (default).
+	EnvListCountSuffix string
+	// EnvIndexedLists enables reading list params from indexed env vars
+	// without a count variable: PREFIX_TAGS_0, PREFIX_TAGS_1 and so on up
+	// to the first missing index. Indexed vars take precedence over
+	// PREFIX_TAGS; a count variable, if enabled and set, takes precedence
+	// over both.
+	EnvIndexedLists bool
+	// EnvFileSuffix enables reading values from files named by env vars,
+	// as for Docker and Kubernetes secrets: with "_FILE", the trimmed
+	// contents of the file at PREFIX_DB_PASSWORD_FILE are used for
+	// db.password, taking precedence over PREFIX_DB_PASSWORD. Empty
+	// disables it (default).
+	EnvFileSuffix string
+	// Interpolate expands ${key} references in string params with the final
+	// value of the referenced param once all params have been loaded, before
+	// validation. "$$" stands for a literal "$".
+	Interpolate bool
+	// PostLoad, if set, is called with each registered config struct after
+	// all parameters have been loaded and validated, e.g. to compute derived
+	// fields. It is skipped if loading failed, and its error is added to the
+	// returned LoadError, with the parameters reverted to their previous
+	// values.
+	PostLoad func(cfg any) error
+	// Logger receives diagnostics about how each key was resolved and any
+	// warnings. If nil, the loader is silent.
+	Logger Logger
+	// Now returns the current time for the checks relative to it, such as
+	// WithinDeadline and TimeBuilder.Future (default: time.Now). Tests can
+	// set a fixed clock to make them deterministic.
+	Now func() time.Time
+}
+
+// Logger is the minimal logging interface used by the loader.
+// Values are never logged, only keys and the sources they came from.
+type Logger interface {
+	Debugf(format string, args ...any)
+	Warnf(format string, args ...any)
 }
 
 // FindConfigFile searches for a configuration file in the given paths.
 // Returns the path of the first existing file, or empty string if none found.
 func FindConfigFile(paths []string) string {
 	for _, p := range paths {
-		// expand ~ to home directory
-		if len(p) > 0 && p[0] == '~' {
-			if home, err := os.UserHomeDir(); err == nil {
-				p = filepath.Join(home, p[1:])
-			}
-		}
-		// expand environment variables
-		p = os.ExpandEnv(p)
-
+		p = expandPath(p)
 		if _, err := os.Stat(p); err == nil {
 			return p
 		}
@@ -42,6 +154,19 @@ func FindConfigFile(paths []string) string {
 	return ""
 }
 
+// expandPath expands a leading ~ to the home directory and environment
+// variables in a config file path.
+func expandPath(p string) string {
+	// expand ~ to home directory
+	if len(p) > 0 && p[0] == '~' {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = filepath.Join(home, p[1:])
+		}
+	}
+	// expand environment variables
+	return os.ExpandEnv(p)
+}
+
 // DefaultConfigPaths returns the default paths to search for config files.
 // The order is: XDG config home, then system-wide /etc.
 func DefaultConfigPaths(appName string) []string {
@@ -66,6 +191,16 @@ func DefaultConfigPaths(appName string) []string {
 type registration struct {
 	prefix string
 	cfg    any
+	// named is set for registrations made with RegisterNamed.
+	named bool
+}
+
+// Module is implemented by config structs that name their own prefix, for
+// Loader.RegisterNamed.
+type Module interface {
+	// ConfigPrefix returns the prefix of all keys in the struct, such as
+	// "db", or an empty prefix for top-level keys.
+	ConfigPrefix() string
 }
 
 // Loader supports modular configuration loading. Modules register their
@@ -74,6 +209,21 @@ type registration struct {
 type Loader struct {
 	opts          Options
 	registrations []registration
+	checks        []check
+	postLoad      []func() error
+	subscribers   []subscriber
+	// snapshot holds the values of the last successful Load, to find the
+	// keys changed by the next one.
+	snapshot map[string]string
+	// stateMu guards warnings and rawConfig, which are read outside loads.
+	stateMu   sync.RWMutex
+	warnings  []Warning
+	rawConfig []byte
+	// secretFiles maps the keys the last successful Load read through
+	// EnvFileSuffix to their files. It is guarded by loadMu.
+	secretFiles map[string]string
+	// loadMu serializes loads, such as those started by Watch.
+	loadMu sync.Mutex
 }
 
 // NewLoader creates a new Loader with the given options.
@@ -88,38 +238,384 @@ func (l *Loader) Register(prefix string, cfg any) {
 	l.registrations = append(l.registrations, registration{prefix: prefix, cfg: cfg})
 }
 
+// RegisterNamed is like Register, taking the prefix from the ConfigPrefix
+// method of cfg, so each module declares its prefix next to its config
+// type. A prefix is meant to belong to a single module: if a config
+// registered with RegisterNamed shares its prefix with any other registered
+// config, Load fails with a PrefixConflictError for each such prefix
+// before reading any source.
+func (l *Loader) RegisterNamed(cfg Module) {
+	l.registrations = append(l.registrations, registration{
+		prefix: cfg.ConfigPrefix(), cfg: cfg, named: true,
+	})
+}
+
+// checkKeys reports keys defined by more than one parameter across the
+// registered configs, which would otherwise all be loaded from the same
+// values with no sign of the mistake.
+func (l *Loader) checkKeys() error {
+	var keys []string
+	types := make(map[string][]string)
+	for _, r := range l.registrations {
+		for _, p := range collectParams(r.cfg, r.prefix) {
+			if _, ok := types[p.key()]; !ok {
+				keys = append(keys, p.key())
+			}
+			types[p.key()] = append(types[p.key()], reflect.TypeOf(r.cfg).String())
+		}
+	}
+	var loadErr LoadError
+	for _, k := range keys {
+		if len(types[k]) > 1 {
+			loadErr.Add(&DuplicateKeyError{Key: k, Types: types[k]})
+		}
+	}
+	if loadErr.HasErrors() {
+		return &loadErr
+	}
+	return nil
+}
+
+// checkPrefixes reports prefixes shared by a RegisterNamed registration and
+// any other registration.
+func (l *Loader) checkPrefixes() error {
+	var loadErr LoadError
+	seen := make(map[string]bool)
+	for _, r := range l.registrations {
+		if !r.named || seen[r.prefix] {
+			continue
+		}
+		seen[r.prefix] = true
+		var types []string
+		for _, other := range l.registrations {
+			if other.prefix == r.prefix {
+				types = append(types, reflect.TypeOf(other.cfg).String())
+			}
+		}
+		if len(types) > 1 {
+			loadErr.Add(&PrefixConflictError{Prefix: r.prefix, Types: types})
+		}
+	}
+	if loadErr.HasErrors() {
+		return &loadErr
+	}
+	return nil
+}
+
 // Load populates all registered config structs from sources.
-// Sources are checked in order of priority: CLI > ENV > YAML > default.
+// By default, sources are checked in order of priority: CLI, environment,
+// dotenv file, YAML, ConfigDir, ConfigMap, then the default.
+// Options.SourceOrder reorders or drops the CLI, the environment along
+// with the dotenv file, and YAML.
+// If loading fails, no parameter is changed, so a failed reload keeps the
+// values of the last successful Load.
 func (l *Loader) Load() error {
+	return l.LoadContext(context.Background())
+}
+
+// LoadContext is like Load, but fails early if ctx is already done, and
+// duration params built with WithinDeadline must fit in the time left
+// before the deadline of ctx, if it has one.
+func (l *Loader) LoadContext(ctx context.Context) error {
+	_, err := l.LoadWithReportContext(ctx)
+	return err
+}
+
+// LoadWithReport is like Load but also returns a LoadReport recording where
+// the value of each parameter came from. The report is returned along with
+// a LoadError, to help find the source of a bad value, and is nil for
+// other errors.
+func (l *Loader) LoadWithReport() (*LoadReport, error) {
+	return l.LoadWithReportContext(context.Background())
+}
+
+// LoadWithReportContext is like LoadWithReport with the context handling of
+// LoadContext.
+func (l *Loader) LoadWithReportContext(ctx context.Context) (*LoadReport, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := l.checkPrefixes(); err != nil {
+		return nil, err
+	}
+	if err := l.checkKeys(); err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	params := l.collectAllParams()
+	if l.opts.AutoHelp && helpRequested(l.opts.Args) {
+		w := l.opts.HelpWriter
+		if w == nil {
+			w = os.Stderr
+		}
+		if err := writeUsage(w, params); err != nil {
+			return nil, err
+		}
+		return nil, ErrHelpRequested
+	}
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
+
+	// resolve into copies and commit the results param by param, so that
+	// concurrent readers never see a value half-way through loading; the
+	// copies start unset so that keys gone from every source lose their value
+	loaded := make([]Param, len(params))
+	for i, p := range params {
+		loaded[i] = cloneParam(p)
+		loaded[i].reset()
+	}
+	run, err := l.resolve(deadline, loaded)
+	if err != nil {
+		return nil, err
+	}
+	l.stateMu.Lock()
+	l.warnings, l.rawConfig = run.warnings, run.rawConfig
+	l.stateMu.Unlock()
+	report := newLoadReport(loaded)
+	if run.loadErr.HasErrors() {
+		return report, &run.loadErr
+	}
+
+	previous := make([]Param, len(params))
+	for i, p := range params {
+		previous[i] = cloneParam(p)
+		p.commit(loaded[i])
+		p.publish()
+	}
+	l.runPostLoad(&run.loadErr)
+	if run.loadErr.HasErrors() {
+		// the hooks rejected the new values, so put the previous ones back
+		for i, p := range params {
+			p.commit(previous[i])
+			p.publish()
+		}
+		return report, &run.loadErr
+	}
+	l.secretFiles = run.secretFiles
+	l.notifyChanges(params)
+	return report, nil
+}
+
+// PostLoad registers a hook called after all parameters have been loaded
+// and validated, in registration order and after Options.PostLoad. Hooks
+// are skipped if loading failed, and their errors are added to the
+// returned LoadError.
+func (l *Loader) PostLoad(fn func() error) {
+	l.postLoad = append(l.postLoad, fn)
+}
+
+// runPostLoad calls the post-load hooks, collecting their errors.
+func (l *Loader) runPostLoad(loadErr *LoadError) {
+	if l.opts.PostLoad != nil {
+		for _, r := range l.registrations {
+			if err := l.opts.PostLoad(r.cfg); err != nil {
+				loadErr.Add(err)
+			}
+		}
+	}
+	for _, fn := range l.postLoad {
+		if err := fn(); err != nil {
+			loadErr.Add(err)
+		}
+	}
+}
+
+// Preview runs the full resolution into a throwaway copy of the registered
+// config structs and returns the would-be value of every key, along with
+// any LoadError. The registered structs are left untouched. Values are not
+// masked, so secrets must be filtered before exposing the result.
+func (l *Loader) Preview() (map[string]any, error) {
+	var params []Param
+	for _, r := range l.registrations {
+		params = append(params, collectParams(cloneConfig(r.cfg), r.prefix)...)
+	}
+	for _, p := range params {
+		p.reset()
+	}
+	run, err := l.resolve(time.Time{}, params)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(params))
+	for _, p := range params {
+		values[p.key()] = p.getAny()
+	}
+	if run.loadErr.HasErrors() {
+		return values, &run.loadErr
+	}
+	return values, nil
+}
+
+// loadRun holds the state of a single resolution pass.
+type loadRun struct {
+	opts      Options
+	sources   []source
+	rawConfig []byte
+	warnings  []Warning
+	loadErr   LoadError
+	// fileSrc is the source of configFile, against whose directory
+	// RelativeToConfig paths are resolved.
+	fileSrc    *yamlSource
+	configFile string
+	// deadline bounds WithinDeadline params; zero means no deadline.
+	deadline time.Time
+	// secretFiles maps the keys read through EnvFileSuffix to their files,
+	// for the SecretRotated mode of Watch.
+	secretFiles map[string]string
+}
+
+// resolve builds the sources and loads the given params from them.
+func (l *Loader) resolve(deadline time.Time, params []Param) (*loadRun, error) {
 	opts := l.opts
 	if opts.ListSeparator == "" {
 		opts.ListSeparator = ","
 	}
+	run, err := newLoadRun(opts, deadline, params)
+	if err != nil {
+		return nil, err
+	}
 
-	configFile := opts.ConfigFile
-	if configFile == "" && len(opts.ConfigPaths) > 0 {
-		configFile = FindConfigFile(opts.ConfigPaths)
+	loaded := make([]Param, 0, len(params))
+	for _, p := range params {
+		if run.loadParam(p) {
+			loaded = append(loaded, p)
+		}
 	}
+	for _, err := range inheritDefaults(params) {
+		run.loadErr.Add(err)
+	}
+	if opts.Interpolate {
+		for _, err := range interpolate(params) {
+			run.loadErr.Add(err)
+		}
+	}
+	for _, p := range loaded {
+		run.checkParam(p)
+	}
+	l.runChecks(params, &run.loadErr)
+	return run, nil
+}
 
-	cliSrc := newCLISource(opts.Args)
-	envSrc := newEnvSource(opts.EnvPrefix)
-	yamlSrc, err := newYAMLSource(configFile)
+// newLoadRun builds the sources described by opts in priority order, with
+// the short flags of params.
+func newLoadRun(opts Options, deadline time.Time, params []Param) (*loadRun, error) {
+	shorts, err := shortFlagsOf(params)
+	if err != nil {
+		return nil, err
+	}
+	cliSrc := newCLISource(opts.Args, shorts)
+	yamlSrc, configFile, err := newFileSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ExpandEnvInYAML {
+		yamlSrc.expandEnv()
+	}
+	envSrcs, err := newEnvSources(opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	sources := []source{cliSrc, envSrc, yamlSrc}
 
-	params := l.collectAllParams()
+	sources := orderSources(opts.SourceOrder, map[SourceKind][]source{
+		SourceCLI:  {cliSrc},
+		SourceEnv:  envSrcs,
+		SourceFile: {yamlSrc},
+	})
+	if opts.ConfigDir != "" {
+		dirSrc, err := newDirSource(opts.ConfigDir)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, dirSrc)
+	}
+	if opts.ConfigMap != nil {
+		sources = append(sources, newMapSource(opts.ConfigMap))
+	}
 
-	loadErr := &LoadError{}
+	run := &loadRun{
+		opts:        opts,
+		deadline:    deadline,
+		sources:     sources,
+		rawConfig:   yamlSrc.raw,
+		fileSrc:     yamlSrc,
+		configFile:  configFile,
+		secretFiles: make(map[string]string),
+	}
+	if opts.DisallowPositionals && len(cliSrc.positionals) > 0 {
+		run.loadErr.Add(&PositionalArgsError{Args: cliSrc.positionals})
+	}
+	return run, nil
+}
+
+// shortFlagsOf maps the short flags of params to their parameters,
+// reporting a flag used by two of them as a ValidationError.
+func shortFlagsOf(params []Param) (map[string]shortFlag, error) {
+	shorts := make(map[string]shortFlag)
 	for _, p := range params {
-		loadParam(p, sources, opts, loadErr)
+		_, boolean := p.getAny().(bool)
+		for _, name := range p.shortFlags() {
+			if other, ok := shorts[name]; ok {
+				return nil, &ValidationError{
+					Key:     p.key(),
+					Value:   "-" + name,
+					Message: fmt.Sprintf("short flag already used by %q", other.key),
+				}
+			}
+			shorts[name] = shortFlag{key: p.key(), boolean: boolean}
+		}
 	}
+	return shorts, nil
+}
 
-	if loadErr.HasErrors() {
-		return loadErr
+// newFileSource reads the YAML config file, or merges the files in
+// MergeConfigFiles, and returns it along with the path of the file read.
+func newFileSource(opts Options) (*yamlSource, string, error) {
+	if len(opts.MergeConfigFiles) > 0 {
+		return newMergedYAMLSource(opts.MergeConfigFiles)
 	}
-	return nil
+	configFile := opts.ConfigFile
+	if configFile == "" && len(opts.ConfigPaths) > 0 {
+		configFile = FindConfigFile(opts.ConfigPaths)
+	}
+	src, err := newYAMLSource(configFile)
+	return src, configFile, err
+}
+
+// newEnvSources returns the environment source followed by the dotenv file
+// source, if any.
+func newEnvSources(opts Options) ([]source, error) {
+	prefixes := envPrefixes(opts)
+	env := newEnvSource(prefixes, opts.EnvListCountSuffix, opts.EnvIndexedLists)
+	env.fileSuffix = opts.EnvFileSuffix
+	sources := []source{env}
+	if opts.DotEnvFile != "" {
+		src, err := newDotEnvSource(
+			opts.DotEnvFile, prefixes, opts.EnvListCountSuffix, opts.EnvIndexedLists,
+		)
+		if err != nil {
+			return nil, err
+		}
+		src.fileSuffix = opts.EnvFileSuffix
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// Warnings returns the non-fatal issues reported by the last Load, such as
+// values that were clamped into range.
+func (l *Loader) Warnings() []Warning {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return slices.Clone(l.warnings)
+}
+
+// RawConfig returns the exact bytes of the config file read by the last
+// Load, or nil if no config file was used.
+func (l *Loader) RawConfig() []byte {
+	l.stateMu.RLock()
+	defer l.stateMu.RUnlock()
+	return slices.Clone(l.rawConfig)
 }
 
 // collectAllParams gathers Param fields from all registered configs.
@@ -133,93 +629,527 @@ func (l *Loader) collectAllParams() []Param {
 
 // Load loads configuration from multiple sources into the provided struct.
 // The struct must contain fields that implement the Param interface.
-// Sources are checked in order of priority: CLI > ENV > YAML > default.
+// By default, sources are checked in order of priority: CLI, environment,
+// dotenv file, YAML, ConfigDir, ConfigMap, then the default.
+// Options.SourceOrder reorders or drops the CLI, the environment along
+// with the dotenv file, and YAML.
+// If loading fails, no parameter is changed.
 func Load(cfg any, opts Options) error {
+	return LoadContext(context.Background(), cfg, opts)
+}
+
+// LoadContext is like Load, but with a context as in Loader.LoadContext.
+func LoadContext(ctx context.Context, cfg any, opts Options) error {
 	l := NewLoader(opts)
 	l.Register("", cfg)
-	return l.Load()
+	return l.LoadContext(ctx)
+}
+
+// orderSources returns the sources of the given kinds in order, or all of
+// them in the default order if order is empty.
+func orderSources(order []SourceKind, byKind map[SourceKind][]source) []source {
+	if len(order) == 0 {
+		order = defaultSourceOrder
+	}
+	sources := make([]source, 0, len(order))
+	for _, kind := range order {
+		for _, src := range byKind[kind] {
+			if !slices.Contains(sources, src) {
+				sources = append(sources, src)
+			}
+		}
+	}
+	return sources
 }
 
-func loadParam(p Param, sources []source, opts Options, loadErr *LoadError) {
+// loadParam sets the value of p from the highest-priority source that has
+// it, reporting whether p was loaded without errors.
+func (r *loadRun) loadParam(p Param) bool {
 	key := p.key()
-	var value any
+	sources := r.sourcesFor(p)
+	value, src, matched, err := resolveValue(p, sources)
+	if err != nil {
+		r.loadErr.Add(err)
+		return false
+	}
+	srcName := r.sourceName(src, matched)
+	if slices.Contains(p.deprecatedAliases(), matched) {
+		r.warn(Warning{Key: key, Message: fmt.Sprintf(
+			"%q from %s is deprecated, use %q instead", matched, srcName, key,
+		)})
+	}
 
-	for _, src := range sources {
-		if v := src.get(key); v != nil {
-			value = v
-			break
+	if value == nil {
+		if ps := presenceSourceOf(p, sources); ps != nil {
+			value, src = true, ps
+			srcName = r.sourceName(src, key)
+		}
+	}
+	if kind, ok := r.kindOf(src); ok {
+		if denied, reject := p.sourceDenied(kind); denied && reject {
+			r.loadErr.Add(&DeniedSourceError{Key: key, Source: srcName})
+			return false
 		}
 	}
 
+	switch {
+	case src != nil:
+		r.debugf("%q resolved from %s", key, srcName)
+	case p.hasDefault():
+		r.debugf("%q not found in any source, using default", key)
+	default:
+		r.debugf("%q not found in any source", key)
+	}
+
 	if value != nil {
 		var setErr error
+		if value, setErr = r.coerceShape(p, value); setErr != nil {
+			r.loadErr.Add(maskSecretError(p, setErr))
+			return false
+		}
 		if s, ok := value.(string); ok {
-			setErr = p.setFromString(s, opts.ListSeparator)
+			setErr = p.setFromString(s, r.opts.ListSeparator)
 		} else {
-			setErr = p.setFromAny(value, opts.ListSeparator)
+			setErr = p.setFromAny(value, r.opts.ListSeparator)
 		}
 		if setErr != nil {
-			loadErr.Add(setErr)
-			return
+			r.loadErr.Add(maskSecretError(p, setErr))
+			return false
+		}
+		kind, _ := r.kindOf(src)
+		p.setOrigin(srcName, kind)
+		if src == source(r.fileSrc) {
+			r.resolvePath(p)
+		}
+		if es, ok := src.(*envSource); ok && es.files[matched] != "" {
+			r.secretFiles[key] = es.files[matched]
+		}
+	}
+	return true
+}
+
+// sourcesFor returns the sources p may be loaded from, leaving out those it
+// ignores with DenySource.
+func (r *loadRun) sourcesFor(p Param) []source {
+	sources := make([]source, 0, len(r.sources))
+	for _, src := range r.sources {
+		if kind, ok := r.kindOf(src); ok {
+			if denied, reject := p.sourceDenied(kind); denied && !reject {
+				continue
+			}
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+// sourceName returns the name of src for provenance: its label in
+// Options.SourceLabels, by the file key was read from or by built-in name,
+// or else the built-in name. Keys of merged files holding a map are not
+// traced to one file. It is empty for a nil src.
+func (r *loadRun) sourceName(src source, key string) string {
+	if src == nil {
+		return ""
+	}
+	if src == source(r.fileSrc) {
+		path := r.configFile
+		if r.fileSrc.files != nil {
+			path = r.fileSrc.files[key]
+		}
+		if label, ok := r.opts.SourceLabels[path]; ok && path != "" {
+			return label
+		}
+	}
+	if label, ok := r.opts.SourceLabels[src.name()]; ok {
+		return label
+	}
+	return src.name()
+}
+
+// kindOf returns the kind of a source.
+func (r *loadRun) kindOf(src source) (SourceKind, bool) {
+	switch {
+	case src == nil:
+		return 0, false
+	case src == source(r.fileSrc):
+		return SourceFile, true
+	}
+	switch s := src.(type) {
+	case *cliSource:
+		return SourceCLI, true
+	case *envSource:
+		return SourceEnv, true
+	case *yamlSource:
+		switch s.label {
+		case "dir":
+			return SourceConfigDir, true
+		case "map":
+			return SourceConfigMap, true
+		}
+		return 0, false
+	default:
+		return 0, false
+	}
+}
+
+// coerceShape checks that a decoded value has the shape p expects: a list
+// for list params and a single value otherwise. Mismatches are reported as
+// a ParseError naming both shapes, unless coerced as enabled in Options.
+// Strings are left alone, as list params split them by the separator.
+func (r *loadRun) coerceShape(p Param, value any) (any, error) {
+	isSeq := isSequence(value)
+	_, isMap := value.(map[string]any)
+	if _, ok := value.(string); ok || isMap {
+		return value, nil
+	}
+	if sp, ok := p.(*StringParam); ok && sp.document {
+		return value, nil
+	}
+	switch list := isList(p); {
+	case list && !isSeq && r.opts.WrapScalarsInLists:
+		return []any{value}, nil
+	case list && !isSeq:
+		return nil, &ParseError{
+			Key:      p.key(),
+			Value:    fmt.Sprintf("%v", value),
+			Expected: typeName(p) + " (got a YAML scalar)",
+		}
+	case !list && isSeq && reflect.ValueOf(value).Len() == 1 && r.opts.UnwrapSingleItemLists:
+		r.warn(Warning{Key: p.key(), Message: "single-item list used as a single value"})
+		return reflect.ValueOf(value).Index(0).Interface(), nil
+	case !list && isSeq:
+		return nil, &ParseError{
+			Key:      p.key(),
+			Value:    fmt.Sprintf("%v", value),
+			Expected: typeName(p) + " (got a YAML sequence)",
+		}
+	}
+	return value, nil
+}
+
+// isSequence reports whether value is a list, such as a YAML sequence or a
+// []string from Options.ConfigMap. A net.IP or []byte is a single value.
+func isSequence(value any) bool {
+	t := reflect.TypeOf(value)
+	return t != nil && t.Kind() == reflect.Slice &&
+		t != reflect.TypeFor[net.IP]() && t != reflect.TypeFor[[]byte]()
+}
+
+// resolvePath joins a relative RelativeToConfig path read from the config
+// file with the directory of that file.
+func (r *loadRun) resolvePath(p Param) {
+	sp, ok := p.(*StringParam)
+	if ok && sp.relativeToConfig && sp.value != "" && !filepath.IsAbs(sp.value) {
+		sp.value = filepath.Join(filepath.Dir(r.configFile), sp.value)
+	}
+}
+
+// checkParam normalizes and validates the loaded value of p. A value that
+// is neither set nor defaulted is not normalized.
+func (r *loadRun) checkParam(p Param) {
+	key := p.key()
+	if p.IsSet() || p.hasDefault() {
+		for _, msg := range p.normalize() {
+			r.warn(Warning{Key: key, Message: msg})
 		}
 	}
 
 	if err := p.validate(); err != nil {
-		loadErr.Add(err)
+		r.loadErr.Add(err)
+	}
+	for _, msg := range p.validateWarn() {
+		r.warn(Warning{Key: key, Message: msg})
 	}
 
-	if p.isRequired() && !p.IsSet() && !p.hasDefault() {
-		loadErr.Add(&RequiredError{Key: key})
+	if !p.IsSet() && !p.hasDefault() {
+		r.checkRequired(p)
 	}
+	r.checkDeadline(p)
+	r.checkTime(p)
 }
 
-// collectParams walks the struct and collects all Param fields with their keys.
-func collectParams(v any, prefix string) []Param {
-	var params []Param
+// envPrefixes returns the env prefixes of opts in priority order.
+func envPrefixes(opts Options) []string {
+	if opts.EnvPrefix == "" {
+		return opts.EnvPrefixes
+	}
+	return append([]string{opts.EnvPrefix}, opts.EnvPrefixes...)
+}
+
+// checkRequired reports p, which has no value, if it is required, either
+// always or in the active profile.
+func (r *loadRun) checkRequired(p Param) {
+	profile := ""
+	if !p.isRequired() {
+		if r.opts.Profile == "" || !slices.Contains(p.requiredProfiles(), r.opts.Profile) {
+			return
+		}
+		profile = r.opts.Profile
+	}
+	err := newRequiredError(r.opts, p)
+	err.Profile = profile
+	r.loadErr.Add(err)
+}
+
+// newRequiredError returns a RequiredError for p with hints on how to set
+// it.
+func newRequiredError(opts Options, p Param) *RequiredError {
+	return &RequiredError{
+		Key:    p.key(),
+		EnvVar: newEnvSource(envPrefixes(opts), "", false).varName(p),
+		Flag:   "--" + p.key(),
+	}
+}
+
+// checkDeadline requires a WithinDeadline duration to fit in the time left
+// before the load deadline.
+func (r *loadRun) checkDeadline(p Param) {
+	dp, ok := p.(*DurationParam)
+	if !ok || !dp.withinDeadline || r.deadline.IsZero() {
+		return
+	}
+	if left := r.deadline.Sub(r.now()); dp.value > left {
+		r.loadErr.Add(&ValidationError{
+			Key:   dp.k,
+			Value: dp.value,
+			Message: fmt.Sprintf(
+				"exceeds the %v left before the load deadline", left.Round(time.Millisecond),
+			),
+		})
+	}
+}
 
+// checkTime requires a TimeParam built with Future or Past to be after or
+// before the current time. A value that is neither set nor defaulted is
+// left to checkRequired.
+func (r *loadRun) checkTime(p Param) {
+	tp, ok := p.(*TimeParam)
+	if !ok || !tp.future && !tp.past || !tp.set && !tp.defaulted() {
+		return
+	}
+	now := r.now()
+	var msg string
+	switch {
+	case tp.future && !tp.value.After(now):
+		msg = "must be in the future"
+	case tp.past && !tp.value.Before(now):
+		msg = "must be in the past"
+	default:
+		return
+	}
+	var value any = tp.value
+	if tp.secret {
+		value = maskedValue
+	}
+	r.loadErr.Add(&ValidationError{Key: tp.k, Value: value, Message: msg})
+}
+
+// now returns the current time from Options.Now, or time.Now if unset.
+func (r *loadRun) now() time.Time {
+	if r.opts.Now != nil {
+		return r.opts.Now()
+	}
+	return time.Now()
+}
+
+// warn records a warning and forwards it to the logger.
+func (r *loadRun) warn(w Warning) {
+	r.warnings = append(r.warnings, w)
+	if r.opts.Logger != nil {
+		r.opts.Logger.Warnf("%s", w)
+	}
+}
+
+func (r *loadRun) debugf(format string, args ...any) {
+	if r.opts.Logger != nil {
+		r.opts.Logger.Debugf(format, args...)
+	}
+}
+
+// resolveValue returns the value of the first key of p (canonical or alias)
+// found in the highest-priority source, that source and the key found, or
+// nil if no source has any of the keys. A value read with the env struct
+// tag is reported with an empty key.
+func resolveValue(p Param, sources []source) (any, source, string, error) {
+	keys := append([]string{p.key()}, p.aliases()...)
+	for _, src := range sources {
+		if v := envTagValue(p, src); v != nil {
+			return v, src, "", nil
+		}
+		for _, k := range keys {
+			fs, ok := src.(formSource)
+			if !ok {
+				if v := src.get(k); v != nil {
+					return v, src, k, nil
+				}
+				continue
+			}
+			v, err := fs.getForms(k, p)
+			if err != nil {
+				return nil, nil, "", err
+			}
+			if v != nil {
+				return v, src, k, nil
+			}
+		}
+	}
+	return nil, nil, "", nil
+}
+
+// envTagValue returns the value of the variable named by the env struct tag
+// of p, if src is an environment source and the variable is set.
+func envTagValue(p Param, src source) any {
+	es, ok := src.(*envSource)
+	if !ok {
+		return nil
+	}
+	name, prefixed := p.envOverride()
+	if name == "" {
+		return nil
+	}
+	return es.getVar(name, prefixed)
+}
+
+// isList reports whether the param holds a slice value. A net.IP is a byte
+// slice but a single value.
+func isList(p Param) bool {
+	t := reflect.TypeOf(p.getAny())
+	return t != nil && t.Kind() == reflect.Slice && t != reflect.TypeFor[net.IP]()
+}
+
+// isMap reports whether the param holds a map value.
+func isMap(p Param) bool {
+	t := reflect.TypeOf(p.getAny())
+	return t != nil && t.Kind() == reflect.Map
+}
+
+// presenceSourceOf returns the first source listing the key of a
+// presence-enabled parameter, or nil if there is none.
+func presenceSourceOf(p Param, sources []source) source {
+	pp, ok := p.(interface{ fromPresence() bool })
+	if !ok || !pp.fromPresence() {
+		return nil
+	}
+	keys := append([]string{p.key()}, p.aliases()...)
+	for _, src := range sources {
+		ps, ok := src.(presenceSource)
+		if !ok {
+			continue
+		}
+		for _, k := range keys {
+			if ps.has(k) {
+				return src
+			}
+		}
+	}
+	return nil
+}
+
+// cloneConfig returns a pointer to a shallow copy of the config struct
+// pointed to by cfg, so that it can be loaded without affecting cfg.
+func cloneConfig(cfg any) any {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return cfg
+	}
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface()
+}
+
+// fieldPlan locates a Param field inside a config struct type.
+type fieldPlan struct {
+	index []int
+	key   string
+	// env and envPrefixed come from the env struct tag.
+	env         string
+	envPrefixed bool
+}
+
+// planCache maps struct types to their []fieldPlan, so the struct walk is
+// done once per type however often a config is loaded or dumped.
+var planCache sync.Map //nolint:gochecknoglobals // process-wide cache keyed by type
+
+// collectParams returns the Param fields of the struct v points to, with
+// their keys set under prefix.
+func collectParams(v any, prefix string) []Param {
 	val := reflect.ValueOf(v)
 	if val.Kind() == reflect.Pointer {
 		val = val.Elem()
 	}
-	if val.Kind() != reflect.Struct {
-		return params
+	if val.Kind() != reflect.Struct || !val.CanAddr() {
+		return nil
 	}
 
-	typ := val.Type()
-	for i := 0; i < val.NumField(); i++ {
-		field := val.Field(i)
-		fieldType := typ.Field(i)
+	plan := planFor(val.Type())
+	params := make([]Param, 0, len(plan))
+	for _, f := range plan {
+		p, ok := val.FieldByIndex(f.index).Addr().Interface().(Param)
+		if !ok {
+			continue
+		}
+		p.setKey(joinKey(prefix, f.key))
+		p.setEnvOverride(f.env, f.envPrefixed)
+		params = append(params, p)
+	}
+	return params
+}
+
+// planFor returns the cached plan of struct type t, building it on first use.
+func planFor(t reflect.Type) []fieldPlan {
+	if cached, ok := planCache.Load(t); ok {
+		if plan, ok := cached.([]fieldPlan); ok {
+			return plan
+		}
+	}
+	plan := buildPlan(t, nil, "")
+	planCache.Store(t, plan)
+	return plan
+}
+
+// buildPlan walks struct type t and returns the location and key of every
+// Param field, recursing into nested structs.
+func buildPlan(t reflect.Type, index []int, prefix string) []fieldPlan {
+	paramType := reflect.TypeFor[Param]()
+	var plan []fieldPlan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
 
 		// get the cfg tag
-		tag := fieldType.Tag.Get("cfg")
-		if tag == "" && !fieldType.Anonymous {
+		tag := field.Tag.Get("cfg")
+		if tag == "" && !field.Anonymous {
 			// skip fields without cfg tag (unless embedded)
 			continue
 		}
 
-		// build the key
-		key := tag
-		if prefix != "" && key != "" {
-			key = prefix + "." + key
-		} else if prefix != "" {
-			key = prefix
-		}
+		key := joinKey(prefix, tag)
+		fieldIndex := append(slices.Clone(index), i)
 
 		// check if field implements Param
-		if field.CanAddr() {
-			if p, ok := field.Addr().Interface().(Param); ok {
-				p.setKey(key)
-				params = append(params, p)
-				continue
-			}
+		if reflect.PointerTo(field.Type).Implements(paramType) {
+			env, opt, _ := strings.Cut(field.Tag.Get("env"), ",")
+			plan = append(plan, fieldPlan{
+				index: fieldIndex, key: key, env: env, envPrefixed: opt == "prefix",
+			})
+			continue
 		}
 
 		// recurse into nested structs
-		if field.Kind() == reflect.Struct {
-			nested := collectParams(field.Addr().Interface(), key)
-			params = append(params, nested...)
+		if field.Type.Kind() == reflect.Struct {
+			plan = append(plan, buildPlan(field.Type, fieldIndex, key)...)
 		}
 	}
+	return plan
+}
 
-	return params
+// joinKey joins two dotted key parts, either of which may be empty.
+func joinKey(prefix, key string) string {
+	switch {
+	case prefix == "":
+		return key
+	case key == "":
+		return prefix
+	default:
+		return prefix + "." + key
+	}
 }
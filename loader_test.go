@@ -1,9 +1,17 @@
 package confetto
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -124,6 +132,247 @@ func TestLoad_EnvVars(t *testing.T) {
 	}
 }
 
+func TestLoad_EnvPrefixes(t *testing.T) {
+	t.Setenv("PLATFORM_DB_HOST", "platform.db.com")
+	t.Setenv("PLATFORM_DB_PORT", "5434")
+	t.Setenv("MYAPP_DB_HOST", "app.db.com")
+	t.Setenv("SHARED_DB_TIMEOUT", "1m")
+
+	cfg := newTestConfig()
+	err := Load(&cfg, Options{EnvPrefix: "MYAPP", EnvPrefixes: []string{"PLATFORM", "SHARED"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.DB.Host.Get() != "app.db.com" {
+		t.Errorf("expected app prefix to win, got %s", cfg.DB.Host.Get())
+	}
+	if cfg.DB.Port.Get() != 5434 {
+		t.Errorf("expected 5434 from platform prefix, got %d", cfg.DB.Port.Get())
+	}
+	if cfg.DB.Timeout.Get() != time.Minute {
+		t.Errorf("expected 1m from shared prefix, got %v", cfg.DB.Timeout.Get())
+	}
+}
+
+func TestLoad_EnvPrefixesPrecedeForms(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "host")
+	if err := os.WriteFile(secret, []byte("platform-file.db.com\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PLATFORM_DB_HOST_FILE", secret)
+	t.Setenv("MYAPP_DB_HOST", "app.db.com")
+	t.Setenv("PLATFORM_TAGS_0", "platform")
+	t.Setenv("MYAPP_TAGS", "a,b")
+
+	type config struct {
+		Host StringParam     `cfg:"db.host"`
+		Tags StringListParam `cfg:"tags"`
+	}
+	cfg := config{Host: String().Build(), Tags: StringList().Build()}
+	err := Load(&cfg, Options{
+		EnvPrefix:       "MYAPP",
+		EnvPrefixes:     []string{"PLATFORM"},
+		EnvFileSuffix:   "_FILE",
+		EnvIndexedLists: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host.Get() != "app.db.com" {
+		t.Errorf("expected app prefix to win over a platform file, got %s", cfg.Host.Get())
+	}
+	if !slices.Equal(cfg.Tags.Get(), []string{"a", "b"}) {
+		t.Errorf("expected app prefix to win over a platform indexed list, got %v", cfg.Tags.Get())
+	}
+}
+
+func TestLoad_EnvFileSuffix(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_DB_HOST_FILE", secret)
+	t.Setenv("APP_DB_HOST", "direct")
+	t.Setenv("APP_SERVER_ADDR_FILE", secret)
+
+	t.Run("Enabled", func(t *testing.T) {
+		cfg := newTestConfig()
+		opts := Options{
+			EnvPrefix:     "APP",
+			EnvFileSuffix: "_FILE",
+			Args:          []string{"--server.addr=:9000"},
+		}
+		if err := Load(&cfg, opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "s3cret" {
+			t.Errorf("expected file contents to win over the env var, got %q", cfg.DB.Host.Get())
+		}
+		if cfg.Server.Addr.Get() != ":9000" {
+			t.Errorf("expected CLI to win over the file, got %q", cfg.Server.Addr.Get())
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		cfg := newTestConfig()
+		if err := Load(&cfg, Options{EnvPrefix: "APP"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "direct" {
+			t.Errorf("expected direct, got %q", cfg.DB.Host.Get())
+		}
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		t.Setenv("APP_DB_HOST_FILE", filepath.Join(t.TempDir(), "missing"))
+		cfg := newTestConfig()
+		err := Load(&cfg, Options{EnvPrefix: "APP", EnvFileSuffix: "_FILE"})
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		fileErr := loadErr.Errors[0]
+		if !errors.Is(fileErr, os.ErrNotExist) || !strings.Contains(fileErr.Error(), "APP_DB_HOST_FILE") {
+			t.Errorf("expected missing file error naming the variable, got %v", fileErr)
+		}
+	})
+}
+
+func TestLoad_EnvTag(t *testing.T) {
+	type config struct {
+		URL   StringParam `cfg:"db.url" env:"DATABASE_URL"`
+		Pool  IntParam    `cfg:"db.pool" env:"POOL,prefix"`
+		Token StringParam `cfg:"token" env:"API_TOKEN"`
+		Name  StringParam `cfg:"name" env:"SERVICE_NAME"`
+	}
+	t.Setenv("DATABASE_URL", "postgres://legacy")
+	t.Setenv("MYAPP_DB_URL", "postgres://derived")
+	t.Setenv("MYAPP_POOL", "20")
+	t.Setenv("MYAPP_TOKEN", "derived-token")
+	t.Setenv("SERVICE_NAME", "from-env")
+
+	cfg := config{
+		URL:   String().Build(),
+		Pool:  Int().Build(),
+		Token: String().Required().Build(),
+		Name:  String().Build(),
+	}
+	err := Load(&cfg, Options{EnvPrefix: "MYAPP", Args: []string{"--name=from-cli"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := cfg.URL.Get(); got != "postgres://legacy" {
+		t.Errorf("expected tagged name to win, got %q", got)
+	}
+	if got := cfg.Pool.Get(); got != 20 {
+		t.Errorf("expected 20 from prefixed tag, got %d", got)
+	}
+	if got := cfg.Token.Get(); got != "derived-token" {
+		t.Errorf("expected derived name as fallback, got %q", got)
+	}
+	if got := cfg.Name.Get(); got != "from-cli" {
+		t.Errorf("expected CLI to override env tag, got %q", got)
+	}
+
+	if err := os.Unsetenv("MYAPP_TOKEN"); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Token = String().Required().Build()
+	err = Load(&cfg, Options{EnvPrefix: "MYAPP"})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var reqErr *RequiredError
+	if !errors.As(loadErr.Errors[0], &reqErr) || reqErr.EnvVar != "API_TOKEN" {
+		t.Errorf("expected RequiredError hinting API_TOKEN, got %v", loadErr.Errors[0])
+	}
+}
+
+func TestLoad_MergeConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	overlay := filepath.Join(dir, "config.prod.yaml")
+	files := map[string]string{
+		base:    "db:\n  host: base.db.com\n  port: 5432\n  timeout: 1m\nserver:\n  addr: \":9090\"\n",
+		overlay: "db:\n  host: prod.db.com\n  port: 6432\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := newTestConfig()
+	l := NewLoader(Options{
+		ConfigFile:       filepath.Join(dir, "ignored.yaml"),
+		MergeConfigFiles: []string{base, filepath.Join(dir, "missing.yaml"), overlay},
+		Args:             []string{"--db.port=7000"},
+	})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.DB.Host.Get() != "prod.db.com" {
+		t.Errorf("expected overlay host, got %s", cfg.DB.Host.Get())
+	}
+	if cfg.DB.Timeout.Get() != time.Minute {
+		t.Errorf("expected base timeout kept in merged db map, got %v", cfg.DB.Timeout.Get())
+	}
+	if cfg.Server.Addr.Get() != ":9090" {
+		t.Errorf("expected base server.addr, got %s", cfg.Server.Addr.Get())
+	}
+	if cfg.DB.Port.Get() != 7000 {
+		t.Errorf("expected CLI to override merged files, got %d", cfg.DB.Port.Get())
+	}
+	if string(l.RawConfig()) != files[overlay] {
+		t.Errorf("expected raw config of the last file, got %q", l.RawConfig())
+	}
+}
+
+func TestLoad_SourceOrder(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := "db:\n  host: yaml.db.com\n  port: 5435\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MYAPP_DB_HOST", "env.db.com")
+	t.Setenv("MYAPP_DB_TIMEOUT", "1m")
+	args := []string{"--db.port=6000", "--db.host=cli.db.com"}
+
+	cfg := newTestConfig()
+	err := Load(&cfg, Options{
+		ConfigFile:  configFile,
+		EnvPrefix:   "MYAPP",
+		Args:        args,
+		SourceOrder: []SourceKind{SourceFile, SourceEnv},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DB.Host.Get() != "yaml.db.com" {
+		t.Errorf("expected file to override env, got %s", cfg.DB.Host.Get())
+	}
+	if cfg.DB.Port.Get() != 5435 {
+		t.Errorf("expected CLI to be left out, got %d", cfg.DB.Port.Get())
+	}
+	if cfg.DB.Timeout.Get() != time.Minute {
+		t.Errorf("expected env to fill keys missing from the file, got %v", cfg.DB.Timeout.Get())
+	}
+
+	cfg = newTestConfig()
+	err = Load(&cfg, Options{ConfigFile: configFile, EnvPrefix: "MYAPP", Args: args})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DB.Host.Get() != "cli.db.com" {
+		t.Errorf("expected default order with CLI first, got %s", cfg.DB.Host.Get())
+	}
+}
+
 func TestLoad_YAMLFile(t *testing.T) {
 	yamlContent := `
 db:
@@ -584,6 +833,59 @@ func TestDefaultConfigPaths_WithXDGConfigHome(t *testing.T) {
 	}
 }
 
+func TestLoad_ExpandEnvInYAML(t *testing.T) {
+	t.Setenv("EXP_DB_HOST", "env.db.com")
+	t.Setenv("EXP_TAG", "blue")
+	yamlContent := `
+host: ${EXP_DB_HOST}
+price: $$5 for $EXP_TAG
+port: 5432
+tags: [a-$EXP_TAG, "${EXP_UNSET}"]
+nested:
+  deep:
+    name: pre-${EXP_TAG}-post
+`
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	type config struct {
+		Host  StringParam     `cfg:"host"`
+		Price StringParam     `cfg:"price"`
+		Port  IntParam        `cfg:"port"`
+		Tags  StringListParam `cfg:"tags"`
+		Name  StringParam     `cfg:"nested.deep.name"`
+	}
+	cfg := config{}
+	if err := Load(&cfg, Options{ConfigFile: configFile, ExpandEnvInYAML: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host.Get() != "env.db.com" {
+		t.Errorf("expected env.db.com, got %q", cfg.Host.Get())
+	}
+	if cfg.Price.Get() != "$5 for blue" {
+		t.Errorf("expected escaped $, got %q", cfg.Price.Get())
+	}
+	if cfg.Port.Get() != 5432 {
+		t.Errorf("expected 5432, got %d", cfg.Port.Get())
+	}
+	if !slices.Equal(cfg.Tags.Get(), []string{"a-blue", ""}) {
+		t.Errorf("expected [a-blue ], got %q", cfg.Tags.Get())
+	}
+	if cfg.Name.Get() != "pre-blue-post" {
+		t.Errorf("expected pre-blue-post, got %q", cfg.Name.Get())
+	}
+
+	cfg = config{}
+	if err := Load(&cfg, Options{ConfigFile: configFile}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host.Get() != "${EXP_DB_HOST}" {
+		t.Errorf("expected no expansion by default, got %q", cfg.Host.Get())
+	}
+}
+
 func TestLoad_ConfigPaths(t *testing.T) {
 	// create temp config file
 	tmpDir := t.TempDir()
@@ -1133,6 +1435,154 @@ type testBookingLoaderConfig struct {
 	Timeout  DurationParam `cfg:"timeout"`
 }
 
+func (*testDBLoaderConfig) ConfigPrefix() string { return "db" }
+
+func (*testBookingLoaderConfig) ConfigPrefix() string { return "booking" }
+
+func TestLoader_RegisterNamed(t *testing.T) {
+	t.Run("Prefixes", func(t *testing.T) {
+		dbCfg := testDBLoaderConfig{Host: String().Default("localhost").Build()}
+		bookingCfg := testBookingLoaderConfig{}
+		l := NewLoader(Options{Args: []string{"--db.host=cli.db.com", "--booking.max_slots=3"}})
+		l.RegisterNamed(&dbCfg)
+		l.RegisterNamed(&bookingCfg)
+
+		if err := l.Load(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dbCfg.Host.Get() != "cli.db.com" || bookingCfg.MaxSlots.Get() != 3 {
+			t.Errorf("expected cli.db.com and 3, got %s and %d",
+				dbCfg.Host.Get(), bookingCfg.MaxSlots.Get())
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		l := NewLoader(Options{})
+		l.RegisterNamed(&testDBLoaderConfig{})
+		l.Register("db", &testServerLoaderConfig{})
+		l.RegisterNamed(&testBookingLoaderConfig{})
+
+		err := l.Load()
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 {
+			t.Fatalf("expected LoadError with 1 error, got %v", err)
+		}
+		var conflict *PrefixConflictError
+		if !errors.As(loadErr.Errors[0], &conflict) {
+			t.Fatalf("expected PrefixConflictError, got %v", loadErr.Errors[0])
+		}
+		want := `prefix "db" is registered by 2 configs: ` +
+			"*confetto.testDBLoaderConfig, *confetto.testServerLoaderConfig"
+		if conflict.Error() != want {
+			t.Errorf("expected %q, got %q", want, conflict.Error())
+		}
+	})
+}
+
+func TestLoader_DuplicateKeys(t *testing.T) {
+	type moduleA struct {
+		Host StringParam `cfg:"db.host"`
+		Port IntParam    `cfg:"db.port"`
+	}
+	type moduleB struct {
+		Host StringParam `cfg:"host"`
+		Name StringParam `cfg:"name"`
+	}
+	a := moduleA{Host: String().Default("a").Build()}
+	b := moduleB{Host: String().Default("b").Build()}
+	l := NewLoader(Options{Args: []string{"--db.host=x"}})
+	l.Register("", &a)
+	l.Register("db", &b)
+
+	err := l.Load()
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 {
+		t.Fatalf("expected LoadError with 1 error, got %v", err)
+	}
+	var dupErr *DuplicateKeyError
+	if !errors.As(loadErr.Errors[0], &dupErr) || dupErr.Key != "db.host" {
+		t.Fatalf("expected DuplicateKeyError for db.host, got %v", loadErr.Errors[0])
+	}
+	want := `key "db.host" is defined by 2 parameters, in ` +
+		"*confetto.moduleA, *confetto.moduleB"
+	if dupErr.Error() != want {
+		t.Errorf("expected %q, got %q", want, dupErr.Error())
+	}
+	if a.Host.IsSet() || b.Host.IsSet() {
+		t.Error("expected no value to be loaded")
+	}
+}
+
+func TestLoader_FailedReloadKeepsValues(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("port: 5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var bound int
+	cfg := struct {
+		Port IntParam `cfg:"port"`
+	}{Port: Int().Validate(Range(1, 10)).BindTo(&bound).Build()}
+	hookCalls := 0
+	l := NewLoader(Options{ConfigFile: configFile})
+	l.Register("", &cfg)
+	l.PostLoad(func() error {
+		hookCalls++
+		if cfg.Port.Get() == 7 {
+			return errors.New("7 is unlucky")
+		}
+		return nil
+	})
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, content := range []string{"port: 50\n", "port: 7\n"} {
+		if err := os.WriteFile(configFile, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Load(); err == nil {
+			t.Fatalf("%s: expected an error", content)
+		}
+		if cfg.Port.Get() != 5 || bound != 5 {
+			t.Errorf("%s: expected 5 to be kept, got %d and bound %d",
+				content, cfg.Port.Get(), bound)
+		}
+	}
+	if hookCalls != 2 {
+		t.Errorf("expected the hook to run for valid values only, got %d calls", hookCalls)
+	}
+}
+
+func TestLoader_ReloadDropsRemovedKeys(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("host: a\nport: 9\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := struct {
+		Host StringParam `cfg:"host"`
+		Port IntParam    `cfg:"port"`
+	}{Port: Int().Default(1).Build()}
+	l := NewLoader(Options{ConfigFile: configFile})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(configFile, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host.IsSet() || cfg.Host.Get() != "" {
+		t.Errorf("expected host to be unset, got %q", cfg.Host.Get())
+	}
+	if cfg.Port.IsSet() || cfg.Port.Get() != 1 || cfg.Port.Source() != SourceDefault {
+		t.Errorf("expected port back to its default, got %d from %v",
+			cfg.Port.Get(), cfg.Port.Source())
+	}
+}
+
 func TestLoader_BasicRegistration(t *testing.T) {
 	os.Setenv("DB_HOST", "env.db.com")
 	os.Setenv("DB_PORT", "5434")
@@ -1435,3 +1885,2073 @@ func TestLoader_EmptyPrefix(t *testing.T) {
 		t.Errorf("expected 5432, got %d", cfg.DB.Port.Get())
 	}
 }
+
+func TestLoad_BoolPresence(t *testing.T) {
+	type featuresConfig struct {
+		Beta   BoolParam `cfg:"beta"`
+		Gamma  BoolParam `cfg:"gamma"`
+		Delta  BoolParam `cfg:"delta"`
+		Legacy BoolParam `cfg:"legacy"`
+	}
+	type presenceConfig struct {
+		Features featuresConfig `cfg:"features"`
+		Flags    struct {
+			Fast BoolParam `cfg:"fast"`
+			Slow BoolParam `cfg:"slow"`
+		} `cfg:"flags"`
+	}
+
+	yamlContent := `
+features:
+  beta:
+  gamma: false
+  legacy:
+flags:
+  - fast
+`
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := presenceConfig{
+		Features: featuresConfig{
+			Beta:   Bool().Presence().Build(),
+			Gamma:  Bool().Presence().Build(),
+			Delta:  Bool().Presence().Build(),
+			Legacy: Bool().Build(),
+		},
+	}
+	cfg.Flags.Fast = Bool().Presence().Build()
+	cfg.Flags.Slow = Bool().Presence().Build()
+
+	err := Load(&cfg, Options{ConfigFile: configFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cfg.Features.Beta.Get() || !cfg.Features.Beta.IsSet() {
+		t.Error("expected features.beta to be true from presence")
+	}
+	if cfg.Features.Gamma.Get() {
+		t.Error("expected explicit false to win over presence")
+	}
+	if cfg.Features.Delta.Get() || cfg.Features.Delta.IsSet() {
+		t.Error("expected absent features.delta to stay unset")
+	}
+	if cfg.Features.Legacy.Get() || cfg.Features.Legacy.IsSet() {
+		t.Error("expected presence to be ignored without opt-in")
+	}
+	if !cfg.Flags.Fast.Get() {
+		t.Error("expected flags.fast to be true from sequence item")
+	}
+	if cfg.Flags.Slow.Get() {
+		t.Error("expected flags.slow to be false")
+	}
+}
+
+func TestLoader_Clamp(t *testing.T) {
+	type clampConfig struct {
+		Percent IntParam      `cfg:"percent"`
+		Ratio   FloatParam    `cfg:"ratio"`
+		Timeout DurationParam `cfg:"timeout"`
+		Workers IntParam      `cfg:"workers"`
+	}
+
+	cfg := clampConfig{
+		Percent: Int().Clamp(0, 100).Build(),
+		Ratio:   Float().Clamp(0, 1).Build(),
+		Timeout: Duration().Default(time.Second).Clamp(time.Second, time.Minute).Build(),
+		Workers: Int().Default(4).Clamp(1, 8).Build(),
+	}
+
+	l := NewLoader(Options{Args: []string{
+		"--percent=150",
+		"--ratio=-0.5",
+		"--timeout=1h",
+	}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Percent.Get() != 100 {
+		t.Errorf("expected 100, got %d", cfg.Percent.Get())
+	}
+	if cfg.Ratio.Get() != 0 {
+		t.Errorf("expected 0, got %f", cfg.Ratio.Get())
+	}
+	if cfg.Timeout.Get() != time.Minute {
+		t.Errorf("expected 1m, got %v", cfg.Timeout.Get())
+	}
+	if cfg.Workers.Get() != 4 {
+		t.Errorf("expected 4, got %d", cfg.Workers.Get())
+	}
+
+	warnings := l.Warnings()
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %v", warnings)
+	}
+	if warnings[0].Key != "percent" || !strings.Contains(warnings[0].Message, "clamped to 100") {
+		t.Errorf("unexpected warning: %v", warnings[0])
+	}
+}
+
+func TestLoad_Alias(t *testing.T) {
+	type aliasDBConfig struct {
+		Host StringParam `cfg:"host"`
+		Port IntParam    `cfg:"port"`
+		Name StringParam `cfg:"name"`
+	}
+	type aliasConfig struct {
+		DB aliasDBConfig `cfg:"db"`
+	}
+
+	yamlContent := `
+db:
+  name: new-name
+database:
+  hostname: old.db.com
+  port: 5433
+  name: old-name
+`
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newCfg := func() aliasConfig {
+		return aliasConfig{
+			DB: aliasDBConfig{
+				Host: String().Alias("database.hostname").Build(),
+				Port: Int().Alias("database.port").Build(),
+				Name: String().Alias("database.name").Build(),
+			},
+		}
+	}
+
+	t.Run("YAML", func(t *testing.T) {
+		cfg := newCfg()
+		if err := Load(&cfg, Options{ConfigFile: configFile}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "old.db.com" {
+			t.Errorf("expected old.db.com, got %s", cfg.DB.Host.Get())
+		}
+		if cfg.DB.Port.Get() != 5433 {
+			t.Errorf("expected 5433, got %d", cfg.DB.Port.Get())
+		}
+		// the canonical key wins over the alias in the same source
+		if cfg.DB.Name.Get() != "new-name" {
+			t.Errorf("expected new-name, got %s", cfg.DB.Name.Get())
+		}
+	})
+
+	t.Run("PriorityAcrossSources", func(t *testing.T) {
+		t.Setenv("APP_DATABASE_PORT", "6000")
+		cfg := newCfg()
+		err := Load(&cfg, Options{
+			ConfigFile: configFile,
+			EnvPrefix:  "APP",
+			Args:       []string{"--database.hostname=cli.db.com"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "cli.db.com" {
+			t.Errorf("expected cli.db.com, got %s", cfg.DB.Host.Get())
+		}
+		if cfg.DB.Port.Get() != 6000 {
+			t.Errorf("expected 6000, got %d", cfg.DB.Port.Get())
+		}
+	})
+}
+
+func TestLoader_RawConfig(t *testing.T) {
+	type rawConfig struct {
+		Host StringParam `cfg:"host"`
+	}
+
+	yamlContent := "# comment\nhost: raw.example.com\n"
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("WithFile", func(t *testing.T) {
+		cfg := rawConfig{Host: String().Build()}
+		l := NewLoader(Options{ConfigFile: configFile})
+		l.Register("", &cfg)
+		if err := l.Load(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(l.RawConfig()) != yamlContent {
+			t.Errorf("expected %q, got %q", yamlContent, l.RawConfig())
+		}
+	})
+
+	t.Run("WithoutFile", func(t *testing.T) {
+		cfg := rawConfig{Host: String().Build()}
+		l := NewLoader(Options{})
+		l.Register("", &cfg)
+		if err := l.Load(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if l.RawConfig() != nil {
+			t.Errorf("expected nil, got %q", l.RawConfig())
+		}
+	})
+}
+
+func TestLoader_WarningsDuringLoad(t *testing.T) {
+	type config struct {
+		Workers IntParam `cfg:"workers"`
+	}
+	cfg := config{Workers: Int().Clamp(1, 8).Build()}
+	l := NewLoader(Options{Args: []string{"--workers=20"}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if err := l.Load(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+		wg.Go(func() {
+			_ = l.Warnings()
+			_ = l.RawConfig()
+			_ = l.Summary()
+		})
+	}
+	wg.Wait()
+
+	warnings := l.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	warnings[0].Message = "changed"
+	if l.Warnings()[0].Message == "changed" {
+		t.Error("expected Warnings to return a copy")
+	}
+}
+
+func TestLoader_Preview(t *testing.T) {
+	cfg := newTestConfig()
+	l := NewLoader(Options{Args: []string{"--db.host=preview.db.com", "--db.port=99999"}})
+	l.Register("", &cfg)
+
+	values, err := l.Preview()
+	if err == nil {
+		t.Fatal("expected validation error for db.port")
+	}
+	if _, ok := err.(*LoadError); !ok {
+		t.Fatalf("expected LoadError, got %T", err)
+	}
+	if values["db.host"] != "preview.db.com" {
+		t.Errorf("expected preview.db.com, got %v", values["db.host"])
+	}
+	if values["db.port"] != 99999 {
+		t.Errorf("expected 99999, got %v", values["db.port"])
+	}
+
+	// the registered struct must be untouched
+	if cfg.DB.Host.Get() != "localhost" || cfg.DB.Host.IsSet() {
+		t.Errorf("expected registered config untouched, got %s", cfg.DB.Host.Get())
+	}
+	if cfg.DB.Port.Get() != 5432 {
+		t.Errorf("expected registered config untouched, got %d", cfg.DB.Port.Get())
+	}
+}
+
+type recordingLogger struct {
+	debug []string
+	warn  []string
+}
+
+func (r *recordingLogger) Debugf(format string, args ...any) {
+	r.debug = append(r.debug, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingLogger) Warnf(format string, args ...any) {
+	r.warn = append(r.warn, fmt.Sprintf(format, args...))
+}
+
+func TestLoad_Logger(t *testing.T) {
+	type loggedConfig struct {
+		Host    StringParam `cfg:"host"`
+		Port    IntParam    `cfg:"port"`
+		Name    StringParam `cfg:"name"`
+		Percent IntParam    `cfg:"percent"`
+	}
+
+	cfg := loggedConfig{
+		Host:    String().Build(),
+		Port:    Int().Default(8080).Build(),
+		Name:    String().Build(),
+		Percent: Int().Clamp(0, 100).Build(),
+	}
+	logger := &recordingLogger{}
+	err := Load(&cfg, Options{
+		Args:   []string{"--host=example.com", "--percent=200"},
+		Logger: logger,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`"host" resolved from cli`,
+		`"port" not found in any source, using default`,
+		`"name" not found in any source`,
+		`"percent" resolved from cli`,
+	}
+	if strings.Join(logger.debug, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected debug messages:\n%s", strings.Join(logger.debug, "\n"))
+	}
+	if len(logger.warn) != 1 || !strings.Contains(logger.warn[0], "clamped to 100") {
+		t.Errorf("unexpected warn messages: %v", logger.warn)
+	}
+}
+
+func TestLoad_DecimalParam(t *testing.T) {
+	type decimalConfig struct {
+		Price DecimalParam `cfg:"price"`
+		Fee   DecimalParam `cfg:"fee"`
+	}
+
+	newCfg := func() decimalConfig {
+		return decimalConfig{
+			Price: Decimal().Scale(2).Build(),
+			Fee:   Decimal().Default(big.NewRat(1, 10)).Build(),
+		}
+	}
+
+	t.Run("CLI", func(t *testing.T) {
+		cfg := newCfg()
+		err := Load(&cfg, Options{Args: []string{"--price=19.9", "--fee=0.0001"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Price.Get().Cmp(big.NewRat(199, 10)) != 0 {
+			t.Errorf("expected 19.9, got %v", cfg.Price.Get())
+		}
+		if cfg.Price.stringValue() != "19.90" {
+			t.Errorf("expected 19.90, got %s", cfg.Price.stringValue())
+		}
+		if cfg.Fee.stringValue() != "0.0001" {
+			t.Errorf("expected 0.0001, got %s", cfg.Fee.stringValue())
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		yamlContent := "price: \"1234567890.12\"\nfee: 0.1\n"
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := newCfg()
+		if err := Load(&cfg, Options{ConfigFile: configFile}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Price.stringValue() != "1234567890.12" {
+			t.Errorf("expected 1234567890.12, got %s", cfg.Price.stringValue())
+		}
+		if cfg.Fee.Get().Cmp(big.NewRat(1, 10)) != 0 {
+			t.Errorf("expected exactly 0.1, got %v", cfg.Fee.Get())
+		}
+	})
+
+	t.Run("ParseErrors", func(t *testing.T) {
+		for _, arg := range []string{"--price=1.234", "--price=abc", "--price=1/3", "--price=1e3"} {
+			cfg := newCfg()
+			err := Load(&cfg, Options{Args: []string{arg}})
+			loadErr, ok := err.(*LoadError)
+			if !ok {
+				t.Fatalf("%s: expected LoadError, got %v", arg, err)
+			}
+			if _, ok := loadErr.Errors[0].(*ParseError); !ok {
+				t.Errorf("%s: expected ParseError, got %v", arg, loadErr.Errors[0])
+			}
+		}
+	})
+}
+
+func TestLoad_EnvListCount(t *testing.T) {
+	type nodesConfig struct {
+		Nodes StringListParam `cfg:"nodes"`
+		Ports IntListParam    `cfg:"ports"`
+	}
+
+	newCfg := func() nodesConfig {
+		return nodesConfig{
+			Nodes: StringList().Build(),
+			Ports: IntList().Build(),
+		}
+	}
+
+	t.Run("Indexed", func(t *testing.T) {
+		t.Setenv("APP_NODES_COUNT", "2")
+		t.Setenv("APP_NODES_0", "a,1")
+		t.Setenv("APP_NODES_1", "b")
+		t.Setenv("APP_NODES_2", "ignored")
+		t.Setenv("APP_PORTS_COUNT", "1")
+		t.Setenv("APP_PORTS_0", "8080")
+
+		cfg := newCfg()
+		err := Load(&cfg, Options{EnvPrefix: "APP", EnvListCountSuffix: "_COUNT"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nodes := cfg.Nodes.Get()
+		if len(nodes) != 2 || nodes[0] != "a,1" || nodes[1] != "b" {
+			t.Errorf("expected [a,1 b], got %v", nodes)
+		}
+		ports := cfg.Ports.Get()
+		if len(ports) != 1 || ports[0] != 8080 {
+			t.Errorf("expected [8080], got %v", ports)
+		}
+	})
+
+	t.Run("MissingIndex", func(t *testing.T) {
+		t.Setenv("APP_NODES_COUNT", "2")
+		t.Setenv("APP_NODES_0", "a")
+
+		cfg := newCfg()
+		err := Load(&cfg, Options{EnvPrefix: "APP", EnvListCountSuffix: "_COUNT"})
+		loadErr, ok := err.(*LoadError)
+		if !ok {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		mee, ok := loadErr.Errors[0].(*MissingEnvError)
+		if !ok {
+			t.Fatalf("expected MissingEnvError, got %v", loadErr.Errors[0])
+		}
+		if mee.Var != "APP_NODES_1" {
+			t.Errorf("expected APP_NODES_1, got %s", mee.Var)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("APP_NODES_COUNT", "1")
+		t.Setenv("APP_NODES_0", "a")
+		t.Setenv("APP_NODES", "x,y")
+
+		cfg := newCfg()
+		if err := Load(&cfg, Options{EnvPrefix: "APP"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		nodes := cfg.Nodes.Get()
+		if len(nodes) != 2 || nodes[0] != "x" {
+			t.Errorf("expected [x y], got %v", nodes)
+		}
+	})
+}
+
+func TestLoad_EnvIndexedLists(t *testing.T) {
+	type nodesConfig struct {
+		Nodes StringListParam `cfg:"nodes"`
+		Ports IntListParam    `cfg:"ports"`
+		Name  StringParam     `cfg:"name"`
+	}
+
+	t.Setenv("APP_NODES", "ignored")
+	t.Setenv("APP_NODES_0", "a,1")
+	t.Setenv("APP_NODES_1", "b")
+	t.Setenv("APP_NODES_3", "after gap")
+	t.Setenv("APP_PORTS", "80,443")
+	t.Setenv("APP_NAME_0", "not a list")
+
+	newCfg := func() nodesConfig {
+		return nodesConfig{
+			Nodes: StringList().Build(),
+			Ports: IntList().Build(),
+			Name:  String().Default("x").Build(),
+		}
+	}
+
+	cfg := newCfg()
+	if err := Load(&cfg, Options{EnvPrefix: "APP", EnvIndexedLists: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nodes := cfg.Nodes.Get(); !reflect.DeepEqual(nodes, []string{"a,1", "b"}) {
+		t.Errorf("expected [a,1 b], got %v", nodes)
+	}
+	if ports := cfg.Ports.Get(); !reflect.DeepEqual(ports, []int{80, 443}) {
+		t.Errorf("expected separator fallback [80 443], got %v", ports)
+	}
+	if cfg.Name.Get() != "x" {
+		t.Errorf("expected indexed vars ignored for scalars, got %s", cfg.Name.Get())
+	}
+
+	cfg = newCfg()
+	if err := Load(&cfg, Options{EnvPrefix: "APP"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nodes := cfg.Nodes.Get(); !reflect.DeepEqual(nodes, []string{"ignored"}) {
+		t.Errorf("expected separator behavior by default, got %v", nodes)
+	}
+}
+
+func TestLoad_Sentinel(t *testing.T) {
+	type limitsConfig struct {
+		MaxConns IntParam      `cfg:"max_conns"`
+		Ratio    FloatParam    `cfg:"ratio"`
+		Timeout  DurationParam `cfg:"timeout"`
+	}
+
+	yamlContent := "max_conns: unlimited\nratio: none\ntimeout: Never\n"
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newCfg := func() limitsConfig {
+		return limitsConfig{
+			MaxConns: Int().Sentinel("unlimited", -1).Sentinel("none", 0).Build(),
+			Ratio:    Float().Sentinel("none", 0).Build(),
+			Timeout:  Duration().Sentinel("never", 0).Build(),
+		}
+	}
+
+	cfg := newCfg()
+	if err := Load(&cfg, Options{ConfigFile: configFile}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxConns.Get() != -1 || !cfg.MaxConns.IsSet() {
+		t.Errorf("expected -1, got %d", cfg.MaxConns.Get())
+	}
+	if cfg.Ratio.Get() != 0 || !cfg.Ratio.IsSet() {
+		t.Errorf("expected 0, got %f", cfg.Ratio.Get())
+	}
+	if cfg.Timeout.Get() != 0 || !cfg.Timeout.IsSet() {
+		t.Errorf("expected 0, got %v", cfg.Timeout.Get())
+	}
+
+	cfg = newCfg()
+	if err := Load(&cfg, Options{Args: []string{"--max_conns=none"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxConns.Get() != 0 {
+		t.Errorf("expected 0, got %d", cfg.MaxConns.Get())
+	}
+
+	cfg = newCfg()
+	if err := Load(&cfg, Options{Args: []string{"--max_conns=infinite"}}); err == nil {
+		t.Error("expected parse error for unknown token")
+	}
+}
+
+func TestLoader_DeprecatedAlias(t *testing.T) {
+	type config struct {
+		Name  StringParam `cfg:"new.name"`
+		Count IntParam    `cfg:"count"`
+	}
+	newConfig := func() config {
+		return config{
+			Name:  String().DeprecatedAlias("old.name").Build(),
+			Count: Int().DeprecatedAlias("num").Default(1).Build(),
+		}
+	}
+
+	cfg := newConfig()
+	l := NewLoader(Options{Args: []string{"--old.name=legacy"}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("expected deprecation not to fail the load, got %v", err)
+	}
+	if cfg.Name.Get() != "legacy" {
+		t.Errorf("expected value from the old key, got %q", cfg.Name.Get())
+	}
+	warnings := l.Warnings()
+	if len(warnings) != 1 || warnings[0].Key != "new.name" ||
+		warnings[0].Message != `"old.name" from cli is deprecated, use "new.name" instead` {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	cfg = newConfig()
+	l = NewLoader(Options{Args: []string{"--old.name=legacy", "--new.name=current"}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Name.Get() != "current" || len(l.Warnings()) != 0 {
+		t.Errorf("expected the new key to win without warnings, got %q and %v",
+			cfg.Name.Get(), l.Warnings())
+	}
+}
+
+func TestLoader_WarnValidate(t *testing.T) {
+	type poolConfig struct {
+		MaxConns IntParam `cfg:"max_conns"`
+	}
+
+	cfg := poolConfig{
+		MaxConns: Int().Default(10).
+			Validate(Positive()).
+			WarnValidate(Range(1, 500)).
+			Build(),
+	}
+
+	l := NewLoader(Options{Args: []string{"--db.max_conns=2000"}})
+	l.Register("db", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.MaxConns.Get() != 2000 {
+		t.Errorf("expected 2000, got %d", cfg.MaxConns.Get())
+	}
+
+	warnings := l.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	if warnings[0].Key != "db.max_conns" || !strings.Contains(warnings[0].Message, "not in range") {
+		t.Errorf("unexpected warning: %v", warnings[0])
+	}
+
+	l = NewLoader(Options{Args: []string{"--db.max_conns=-1"}})
+	l.Register("db", &cfg)
+	if err := l.Load(); err == nil {
+		t.Error("expected error-level validator to still fail")
+	}
+}
+
+func TestLoader_ClampSkipsUnset(t *testing.T) {
+	type clampConfig struct {
+		Workers IntParam `cfg:"workers"`
+	}
+
+	cfg := clampConfig{Workers: Int().Clamp(1, 8).Build()}
+	l := NewLoader(Options{})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Workers.Get() != 0 || cfg.Workers.IsSet() {
+		t.Errorf("expected unset zero value, got %d", cfg.Workers.Get())
+	}
+	if warnings := l.Warnings(); len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+}
+
+func TestLoader_SecretValuesMaskedInWarnings(t *testing.T) {
+	type pinConfig struct {
+		Pin  IntParam `cfg:"pin"`
+		Code IntParam `cfg:"code"`
+	}
+
+	cfg := pinConfig{
+		Pin:  Int().Secret().Clamp(0, 9999).Build(),
+		Code: Int().Secret().WarnValidate(Range(0, 999)).Build(),
+	}
+
+	l := NewLoader(Options{Args: []string{"--pin=123456", "--code=4242"}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	warnings := l.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %v", warnings)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w.Message, "123456") || strings.Contains(w.Message, "9999") ||
+			strings.Contains(w.Message, "4242") {
+			t.Errorf("secret value leaked in warning: %v", w)
+		}
+		if !strings.Contains(w.Message, maskedValue) {
+			t.Errorf("expected masked value in warning, got %v", w)
+		}
+	}
+}
+
+func TestLoad_ConfigMap(t *testing.T) {
+	configMap := map[string]any{
+		"db": map[string]any{
+			"host":    "map.db.com",
+			"port":    5436,
+			"timeout": "3m",
+		},
+		"server": map[string]any{
+			"addr": ":7070",
+		},
+	}
+
+	t.Run("Alone", func(t *testing.T) {
+		cfg := newTestConfig()
+		if err := Load(&cfg, Options{ConfigMap: configMap}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "map.db.com" {
+			t.Errorf("expected map.db.com, got %s", cfg.DB.Host.Get())
+		}
+		if cfg.DB.Port.Get() != 5436 {
+			t.Errorf("expected 5436, got %d", cfg.DB.Port.Get())
+		}
+		if cfg.DB.Timeout.Get() != 3*time.Minute {
+			t.Errorf("expected 3m, got %v", cfg.DB.Timeout.Get())
+		}
+	})
+
+	t.Run("BelowFile", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		if err := os.WriteFile(configFile, []byte("db:\n  host: file.db.com\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := newTestConfig()
+		err := Load(&cfg, Options{
+			ConfigFile: configFile,
+			ConfigMap:  configMap,
+			Args:       []string{"--server.addr=:6060"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "file.db.com" {
+			t.Errorf("expected file.db.com, got %s", cfg.DB.Host.Get())
+		}
+		if cfg.DB.Port.Get() != 5436 {
+			t.Errorf("expected 5436, got %d", cfg.DB.Port.Get())
+		}
+		if cfg.Server.Addr.Get() != ":6060" {
+			t.Errorf("expected :6060, got %s", cfg.Server.Addr.Get())
+		}
+	})
+}
+
+func TestLoad_StringFromYAMLCollection(t *testing.T) {
+	type docConfig struct {
+		Name     StringParam `cfg:"name"`
+		Template StringParam `cfg:"template"`
+	}
+
+	yamlContent := `
+name:
+  first: accidentally
+  last: nested
+template:
+  kind: alert
+  labels: [a, b]
+`
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("MappingRejected", func(t *testing.T) {
+		cfg := docConfig{Name: String().Build(), Template: String().Document().Build()}
+		err := Load(&cfg, Options{ConfigFile: configFile})
+		loadErr, ok := err.(*LoadError)
+		if !ok {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		if len(loadErr.Errors) != 1 {
+			t.Fatalf("expected 1 error, got %v", loadErr.Errors)
+		}
+		pe, ok := loadErr.Errors[0].(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %v", loadErr.Errors[0])
+		}
+		if pe.Key != "name" || !strings.Contains(pe.Error(), "got a YAML mapping") {
+			t.Errorf("unexpected error: %v", pe)
+		}
+	})
+
+	t.Run("DocumentReserialized", func(t *testing.T) {
+		cfg := docConfig{Name: String().Document().Build(), Template: String().Document().Build()}
+		if err := Load(&cfg, Options{ConfigFile: configFile}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "kind: alert\nlabels:\n    - a\n    - b\n"
+		if cfg.Template.Get() != expected {
+			t.Errorf("expected %q, got %q", expected, cfg.Template.Get())
+		}
+	})
+}
+
+func TestLoad_DisallowPositionals(t *testing.T) {
+	t.Run("Rejected", func(t *testing.T) {
+		cfg := newTestConfig()
+		args := []string{"db.host=typo.db.com", "--db.port", "5433", "serve", "--", "extra"}
+		err := Load(&cfg, Options{Args: args, DisallowPositionals: true})
+		loadErr, ok := err.(*LoadError)
+		if !ok {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		pae, ok := loadErr.Errors[0].(*PositionalArgsError)
+		if !ok {
+			t.Fatalf("expected PositionalArgsError, got %v", loadErr.Errors[0])
+		}
+		if strings.Join(pae.Args, " ") != "db.host=typo.db.com serve" {
+			t.Errorf("unexpected positionals: %v", pae.Args)
+		}
+	})
+
+	t.Run("AllowedByDefault", func(t *testing.T) {
+		cfg := newTestConfig()
+		err := Load(&cfg, Options{Args: []string{"serve", "--db.port=5433"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("AfterTerminator", func(t *testing.T) {
+		cfg := newTestConfig()
+		args := []string{"--db.port=5433", "--", "file.txt", "--db.host=ignored"}
+		err := Load(&cfg, Options{Args: args, DisallowPositionals: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.DB.Host.Get() != "localhost" {
+			t.Errorf("expected args after -- to be ignored, got %s", cfg.DB.Host.Get())
+		}
+	})
+}
+
+func TestLoad_ShortFlags(t *testing.T) {
+	type config struct {
+		Verbose BoolParam   `cfg:"verbose"`
+		Force   BoolParam   `cfg:"force"`
+		Port    IntParam    `cfg:"server.port"`
+		Host    StringParam `cfg:"server.host"`
+	}
+	newConfig := func() config {
+		return config{
+			Verbose: Bool().Short("v").Build(),
+			Force:   Bool().Short("-f").Build(),
+			Port:    Int().Short("p").Default(8080).Build(),
+			Host:    String().Short("H").Default("localhost").Build(),
+		}
+	}
+
+	t.Run("Parsed", func(t *testing.T) {
+		cfg := newConfig()
+		args := []string{"-v", "-p", "9090", "-H=example.com", "serve"}
+		err := Load(&cfg, Options{Args: args})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.Verbose.Get() || cfg.Force.Get() {
+			t.Errorf("expected only verbose, got %v and %v", cfg.Verbose.Get(), cfg.Force.Get())
+		}
+		if cfg.Port.Get() != 9090 || cfg.Host.Get() != "example.com" {
+			t.Errorf("unexpected port and host: %d, %s", cfg.Port.Get(), cfg.Host.Get())
+		}
+	})
+
+	t.Run("Combined", func(t *testing.T) {
+		cfg := newConfig()
+		err := Load(&cfg, Options{Args: []string{"-vf", "-fAsL", "-x"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.Verbose.Get() || !cfg.Force.Get() {
+			t.Errorf("expected -vf to set both, got %v and %v", cfg.Verbose.Get(), cfg.Force.Get())
+		}
+	})
+
+	t.Run("AttachedValue", func(t *testing.T) {
+		cfg := newConfig()
+		err := Load(&cfg, Options{Args: []string{"-p9090", "-Hexample.com"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Port.Get() != 9090 || cfg.Host.Get() != "example.com" {
+			t.Errorf("unexpected port and host: %d, %s", cfg.Port.Get(), cfg.Host.Get())
+		}
+	})
+
+	t.Run("CombinedWithValue", func(t *testing.T) {
+		cfg := newConfig()
+		err := Load(&cfg, Options{Args: []string{"-vp9090", "-fH", "example.com", "serve"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !cfg.Verbose.Get() || !cfg.Force.Get() {
+			t.Errorf("expected both booleans set, got %v and %v", cfg.Verbose.Get(), cfg.Force.Get())
+		}
+		if cfg.Port.Get() != 9090 || cfg.Host.Get() != "example.com" {
+			t.Errorf("unexpected port and host: %d, %s", cfg.Port.Get(), cfg.Host.Get())
+		}
+
+		cfg = newConfig()
+		if err := Load(&cfg, Options{Args: []string{"-xp9090"}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Port.Get() != 8080 {
+			t.Errorf("expected a cluster with an unknown letter ignored, got %d", cfg.Port.Get())
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Force = Bool().Short("v").Build()
+		err := Load(&cfg, Options{})
+		var valErr *ValidationError
+		if !errors.As(err, &valErr) || valErr.Key != "force" {
+			t.Errorf("expected ValidationError for force, got %v", err)
+		}
+	})
+}
+
+func TestLoad_NestedStringListParam(t *testing.T) {
+	type routesConfig struct {
+		Routes NestedStringListParam `cfg:"routes"`
+	}
+
+	t.Run("CLI", func(t *testing.T) {
+		cfg := routesConfig{Routes: NestedStringList().Build()}
+		err := Load(&cfg, Options{Args: []string{"--routes=a:1|b:2|c:3"}, ListSeparator: "|"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := fmt.Sprintf("%v", cfg.Routes.Get())
+		if got != "[[a 1] [b 2] [c 3]]" {
+			t.Errorf("expected [[a 1] [b 2] [c 3]], got %s", got)
+		}
+	})
+
+	t.Run("InnerSeparator", func(t *testing.T) {
+		cfg := routesConfig{Routes: NestedStringList().InnerSeparator("=").Build()}
+		err := Load(&cfg, Options{Args: []string{"--routes=a=1,b=2"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := fmt.Sprintf("%v", cfg.Routes.Get())
+		if got != "[[a 1] [b 2]]" {
+			t.Errorf("expected [[a 1] [b 2]], got %s", got)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		yamlContent := "routes:\n  - [a, 1]\n  - \"b:2\"\n"
+		tmpDir := t.TempDir()
+		configFile := filepath.Join(tmpDir, "config.yaml")
+		if err := os.WriteFile(configFile, []byte(yamlContent), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := routesConfig{Routes: NestedStringList().Build()}
+		if err := Load(&cfg, Options{ConfigFile: configFile}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := fmt.Sprintf("%v", cfg.Routes.Get())
+		if got != "[[a 1] [b 2]]" {
+			t.Errorf("expected [[a 1] [b 2]], got %s", got)
+		}
+	})
+}
+
+func TestLoad_NormalizeOneOf(t *testing.T) {
+	type envConfig struct {
+		Env StringParam `cfg:"env"`
+	}
+
+	cfg := envConfig{Env: String().Default("dev").NormalizeOneOf("dev", "staging", "prod").Build()}
+	if err := Load(&cfg, Options{Args: []string{"--env=PROD"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Env.Get() != "prod" {
+		t.Errorf("expected prod, got %s", cfg.Env.Get())
+	}
+
+	cfg = envConfig{Env: String().NormalizeOneOf("dev", "staging", "prod").Build()}
+	err := Load(&cfg, Options{Args: []string{"--env=Production"}})
+	loadErr, ok := err.(*LoadError)
+	if !ok {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	if _, ok := loadErr.Errors[0].(*ValidationError); !ok {
+		t.Errorf("expected ValidationError, got %v", loadErr.Errors[0])
+	}
+}
+
+func TestLoader_PostLoad(t *testing.T) {
+	type serverConfig struct {
+		Host    StringParam `cfg:"host"`
+		Port    IntParam    `cfg:"port"`
+		Address string
+	}
+
+	cfg := serverConfig{
+		Host: String().Default("localhost").Build(),
+		Port: Int().Default(8080).Validate(Range(1, 65535)).Build(),
+	}
+	var seen []any
+	l := NewLoader(Options{
+		Args: []string{"--server.port=9090"},
+		PostLoad: func(c any) error {
+			seen = append(seen, c)
+			return nil
+		},
+	})
+	l.Register("server", &cfg)
+	l.PostLoad(func() error {
+		cfg.Address = fmt.Sprintf("%s:%d", cfg.Host.Get(), cfg.Port.Get())
+		return nil
+	})
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Address != "localhost:9090" {
+		t.Errorf("expected derived address, got %q", cfg.Address)
+	}
+	if len(seen) != 1 || seen[0] != &cfg {
+		t.Errorf("expected Options.PostLoad to receive the registered struct, got %v", seen)
+	}
+
+	hookErr := errors.New("address not reachable")
+	l.PostLoad(func() error { return hookErr })
+	err := l.Load()
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 || loadErr.Errors[0] != hookErr {
+		t.Fatalf("expected hook error in LoadError, got %v", err)
+	}
+
+	called := false
+	l = NewLoader(Options{Args: []string{"--server.port=0"}})
+	l.Register("server", &cfg)
+	l.PostLoad(func() error {
+		called = true
+		return nil
+	})
+	if err := l.Load(); err == nil {
+		t.Fatal("expected validation error")
+	}
+	if called {
+		t.Error("expected hook to be skipped after a failed load")
+	}
+}
+
+func TestLoad_ConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// mimic the layout of a mounted ConfigMap: keys are links into ..data
+	write("..2026_10_16/port", "5437\n")
+	write("..2026_10_16/db/host", "ignored\n")
+	if err := os.Symlink("..2026_10_16", filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	write("db/host", "dir.db.com\n")
+	if err := os.Symlink(
+		filepath.Join("..", "..data", "port"), filepath.Join(dir, "db", "port"),
+	); err != nil {
+		t.Fatal(err)
+	}
+	write("server/addr", ":5050\n")
+
+	cfg := newTestConfig()
+	err := Load(&cfg, Options{
+		ConfigDir: dir,
+		ConfigMap: map[string]any{"server": map[string]any{"addr": ":7070"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.DB.Host.Get() != "dir.db.com" {
+		t.Errorf("expected dir.db.com, got %q", cfg.DB.Host.Get())
+	}
+	if cfg.DB.Port.Get() != 5437 {
+		t.Errorf("expected 5437 through symlink, got %d", cfg.DB.Port.Get())
+	}
+	if cfg.Server.Addr.Get() != ":5050" || cfg.Server.Addr.origin() != "dir" {
+		t.Errorf("expected :5050 from dir, got %q from %s",
+			cfg.Server.Addr.Get(), cfg.Server.Addr.origin())
+	}
+
+	cfg = newTestConfig()
+	if err := Load(&cfg, Options{ConfigDir: filepath.Join(dir, "missing")}); err != nil {
+		t.Errorf("expected missing dir to be ignored, got %v", err)
+	}
+}
+
+func TestLoad_LenientBool(t *testing.T) {
+	type config struct {
+		Debug BoolParam     `cfg:"debug"`
+		Flags BoolListParam `cfg:"flags"`
+		Beta  BoolParam     `cfg:"beta"`
+	}
+	newConfig := func() config {
+		return config{
+			Debug: Bool().Lenient().Build(),
+			Flags: BoolList().Lenient().Build(),
+			Beta:  Bool().Build(),
+		}
+	}
+
+	cfg := newConfig()
+	err := Load(&cfg, Options{
+		Args:      []string{"--debug=YES", "--flags=yes,no,true,0"},
+		ConfigMap: map[string]any{"flags": []any{"on"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Debug.Get() {
+		t.Error("expected debug to be true")
+	}
+	if got := cfg.Flags.Get(); !slices.Equal(got, []bool{true, false, true, false}) {
+		t.Errorf("expected [true false true false], got %v", got)
+	}
+
+	cfg = newConfig()
+	if err := Load(&cfg, Options{ConfigMap: map[string]any{"flags": []any{"off", "Y"}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Flags.Get(); !slices.Equal(got, []bool{false, true}) {
+		t.Errorf("expected [false true], got %v", got)
+	}
+
+	cfg = newConfig()
+	if err := Load(&cfg, Options{Args: []string{"--beta=yes"}}); err == nil {
+		t.Error("expected strict bool to reject yes")
+	}
+}
+
+func TestLoadContext_WithinDeadline(t *testing.T) {
+	type config struct {
+		RetryBudget DurationParam `cfg:"retry_budget"`
+	}
+	newConfig := func() config {
+		return config{RetryBudget: Duration().Default(time.Second).WithinDeadline().Build()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cfg := newConfig()
+	if err := LoadContext(ctx, &cfg, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg = newConfig()
+	err := LoadContext(ctx, &cfg, Options{Args: []string{"--retry_budget=5m"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var ve *ValidationError
+	if !errors.As(loadErr.Errors[0], &ve) || ve.Key != "retry_budget" {
+		t.Errorf("expected ValidationError for retry_budget, got %v", loadErr.Errors[0])
+	}
+
+	cfg = newConfig()
+	if err := Load(&cfg, Options{Args: []string{"--retry_budget=5m"}}); err != nil {
+		t.Errorf("expected no check without a deadline, got %v", err)
+	}
+
+	cancel()
+	if err := LoadContext(ctx, &cfg, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestLoad_Now(t *testing.T) {
+	type config struct {
+		Expiry      TimeParam     `cfg:"expiry"`
+		Activation  TimeParam     `cfg:"activation"`
+		RetryBudget DurationParam `cfg:"retry_budget"`
+	}
+	newConfig := func() config {
+		return config{
+			Expiry:      Time().Future().Build(),
+			Activation:  Time().Past().Build(),
+			RetryBudget: Duration().WithinDeadline().Build(),
+		}
+	}
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	opts := Options{Now: func() time.Time { return now }}
+
+	cfg := newConfig()
+	opts.Args = []string{"--expiry=2030-06-01T00:00:00Z", "--activation=2029-06-01T00:00:00Z"}
+	if err := Load(&cfg, opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg = newConfig()
+	opts.Args = []string{"--expiry=2029-06-01T00:00:00Z", "--activation=2030-06-01T00:00:00Z"}
+	err := Load(&cfg, opts)
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected LoadError with two errors, got %v", err)
+	}
+	for i, w := range []struct{ key, msg string }{
+		{"expiry", "must be in the future"},
+		{"activation", "must be in the past"},
+	} {
+		var ve *ValidationError
+		if !errors.As(loadErr.Errors[i], &ve) || ve.Key != w.key || ve.Message != w.msg {
+			t.Errorf("expected %q for %s, got %v", w.msg, w.key, loadErr.Errors[i])
+		}
+	}
+
+	// the deadline is measured from Options.Now, not the wall clock
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Hour))
+	defer cancel()
+	cfg = newConfig()
+	opts.Now = func() time.Time { return time.Now().Add(59 * time.Minute) }
+	opts.Args = []string{"--retry_budget=5m"}
+	if err := LoadContext(ctx, &cfg, opts); !errors.As(err, &loadErr) {
+		t.Errorf("expected the budget to exceed the time left on the clock, got %v", err)
+	}
+}
+
+func TestBuilder_BindTo(t *testing.T) {
+	var (
+		legacyHost    = "legacy.example.com"
+		legacyPort    = 1
+		legacyTimeout time.Duration
+		legacyTags    []string
+	)
+	type config struct {
+		Host    StringParam     `cfg:"host"`
+		Port    IntParam        `cfg:"port"`
+		Timeout DurationParam   `cfg:"timeout"`
+		Tags    StringListParam `cfg:"tags"`
+	}
+	cfg := config{
+		Host:    String().BindTo(&legacyHost).Build(),
+		Port:    Int().BindTo(&legacyPort).Default(8080).Build(),
+		Timeout: Duration().Default(time.Second).BindTo(&legacyTimeout).Build(),
+		Tags:    StringList().BindTo(&legacyTags).Build(),
+	}
+	if legacyPort != 8080 || legacyTimeout != time.Second {
+		t.Errorf("expected defaults written on Build, got %d and %v", legacyPort, legacyTimeout)
+	}
+
+	err := Load(&cfg, Options{Args: []string{"--port=9090", "--tags=a,b"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if legacyHost != "legacy.example.com" {
+		t.Errorf("expected unset param to leave variable alone, got %q", legacyHost)
+	}
+	if legacyPort != 9090 || legacyTimeout != time.Second {
+		t.Errorf("expected loaded values written, got %d and %v", legacyPort, legacyTimeout)
+	}
+	if !slices.Equal(legacyTags, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", legacyTags)
+	}
+}
+
+// benchModule and benchConfig make up a 300-field config.
+type benchModule struct {
+	F0 IntParam `cfg:"f0"`
+	F1 IntParam `cfg:"f1"`
+	F2 IntParam `cfg:"f2"`
+	F3 IntParam `cfg:"f3"`
+	F4 IntParam `cfg:"f4"`
+	F5 IntParam `cfg:"f5"`
+	F6 IntParam `cfg:"f6"`
+	F7 IntParam `cfg:"f7"`
+	F8 IntParam `cfg:"f8"`
+	F9 IntParam `cfg:"f9"`
+}
+
+type benchConfig struct {
+	M00 benchModule `cfg:"m00"`
+	M01 benchModule `cfg:"m01"`
+	M02 benchModule `cfg:"m02"`
+	M03 benchModule `cfg:"m03"`
+	M04 benchModule `cfg:"m04"`
+	M05 benchModule `cfg:"m05"`
+	M06 benchModule `cfg:"m06"`
+	M07 benchModule `cfg:"m07"`
+	M08 benchModule `cfg:"m08"`
+	M09 benchModule `cfg:"m09"`
+	M10 benchModule `cfg:"m10"`
+	M11 benchModule `cfg:"m11"`
+	M12 benchModule `cfg:"m12"`
+	M13 benchModule `cfg:"m13"`
+	M14 benchModule `cfg:"m14"`
+	M15 benchModule `cfg:"m15"`
+	M16 benchModule `cfg:"m16"`
+	M17 benchModule `cfg:"m17"`
+	M18 benchModule `cfg:"m18"`
+	M19 benchModule `cfg:"m19"`
+	M20 benchModule `cfg:"m20"`
+	M21 benchModule `cfg:"m21"`
+	M22 benchModule `cfg:"m22"`
+	M23 benchModule `cfg:"m23"`
+	M24 benchModule `cfg:"m24"`
+	M25 benchModule `cfg:"m25"`
+	M26 benchModule `cfg:"m26"`
+	M27 benchModule `cfg:"m27"`
+	M28 benchModule `cfg:"m28"`
+	M29 benchModule `cfg:"m29"`
+}
+
+func BenchmarkCollectParams(b *testing.B) {
+	var cfg benchConfig
+	typ := reflect.TypeOf(cfg)
+
+	b.Run("Cached", func(b *testing.B) {
+		for b.Loop() {
+			collectParams(&cfg, "app")
+		}
+	})
+	b.Run("Uncached", func(b *testing.B) {
+		for b.Loop() {
+			planCache.Delete(typ)
+			collectParams(&cfg, "app")
+		}
+	})
+}
+
+func TestCollectParams_PlanPerType(t *testing.T) {
+	type a struct {
+		X IntParam `cfg:"x"`
+	}
+	type b struct {
+		X IntParam `cfg:"y"`
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			var cfgA a
+			var cfgB b
+			pa := collectParams(&cfgA, "p")
+			pb := collectParams(&cfgB, "")
+			if len(pa) != 1 || pa[0].key() != "p.x" {
+				t.Errorf("unexpected params for a: %v", pa)
+			}
+			if len(pb) != 1 || pb[0].key() != "y" {
+				t.Errorf("unexpected params for b: %v", pb)
+			}
+		})
+	}
+	wg.Wait()
+
+	var cfg benchConfig
+	params := collectParams(&cfg, "")
+	if len(params) != 300 || params[299].key() != "m29.f9" {
+		t.Errorf("unexpected params: %d, last %q", len(params), params[len(params)-1].key())
+	}
+	if params[0] != &cfg.M00.F0 {
+		t.Error("expected params to point into the struct")
+	}
+}
+
+func TestLoad_RelativeToConfig(t *testing.T) {
+	type tlsConfig struct {
+		CertFile StringParam `cfg:"cert_file"`
+		KeyFile  StringParam `cfg:"key_file"`
+		CAFile   StringParam `cfg:"ca_file"`
+		Plain    StringParam `cfg:"plain"`
+	}
+
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	content := "cert_file: certs/server.pem\nkey_file: /etc/ssl/server.key\nplain: certs/x\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := tlsConfig{
+		CertFile: String().RelativeToConfig().Build(),
+		KeyFile:  String().RelativeToConfig().Build(),
+		CAFile:   String().RelativeToConfig().Build(),
+		Plain:    String().Build(),
+	}
+	err := Load(&cfg, Options{
+		ConfigFile: configFile,
+		Args:       []string{"--ca_file=ca.pem"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.CertFile.Get(); got != filepath.Join(tmpDir, "certs", "server.pem") {
+		t.Errorf("expected path relative to config dir, got %q", got)
+	}
+	if got := cfg.KeyFile.Get(); got != "/etc/ssl/server.key" {
+		t.Errorf("expected absolute path unchanged, got %q", got)
+	}
+	if got := cfg.CAFile.Get(); got != "ca.pem" {
+		t.Errorf("expected CLI path unchanged, got %q", got)
+	}
+	if got := cfg.Plain.Get(); got != "certs/x" {
+		t.Errorf("expected plain string unchanged, got %q", got)
+	}
+}
+
+func TestLoad_Percentage(t *testing.T) {
+	type samplingConfig struct {
+		Rate   FloatParam `cfg:"rate"`
+		Errors FloatParam `cfg:"errors"`
+		Traces FloatParam `cfg:"traces"`
+	}
+	newConfig := func() samplingConfig {
+		return samplingConfig{
+			Rate:   Float().Default(10).Percentage().Build(),
+			Errors: Float().Percentage().Default(100).Build(),
+			Traces: Float().Validate(Probability()).Build(),
+		}
+	}
+
+	cfg := newConfig()
+	if cfg.Rate.Get() != 0.1 || cfg.Errors.Get() != 1 {
+		t.Errorf("expected defaults as fractions, got %v and %v", cfg.Rate.Get(), cfg.Errors.Get())
+	}
+	err := Load(&cfg, Options{
+		Args:      []string{"--rate=25%", "--traces=0.5"},
+		ConfigMap: map[string]any{"errors": 50},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Rate.Get() != 0.25 || cfg.Errors.Get() != 0.5 || cfg.Traces.Get() != 0.5 {
+		t.Errorf("unexpected values: %v %v %v", cfg.Rate.Get(), cfg.Errors.Get(), cfg.Traces.Get())
+	}
+
+	cfg = newConfig()
+	err = Load(&cfg, Options{Args: []string{"--rate=150", "--traces=5"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", err)
+	}
+	if !strings.Contains(loadErr.Errors[0].Error(), "150% is not in range") {
+		t.Errorf("expected percentage range error, got %v", loadErr.Errors[0])
+	}
+	if !strings.Contains(loadErr.Errors[1].Error(), "not a probability") {
+		t.Errorf("expected probability error, got %v", loadErr.Errors[1])
+	}
+}
+
+func TestLoad_PercentageBuilderOrder(t *testing.T) {
+	type config struct {
+		Before FloatParam `cfg:"before"`
+		After  FloatParam `cfg:"after"`
+		Twice  FloatParam `cfg:"twice"`
+	}
+	cfg := config{
+		Before: Float().Clamp(0, 0.5).Sentinel("off", 0).Percentage().Default(20).Build(),
+		After:  Float().Default(20).Percentage().Clamp(0, 0.5).Sentinel("off", 0).Build(),
+		Twice:  Float().Percentage().Percentage().Build(),
+	}
+	if len(cfg.Twice.validators) != 1 {
+		t.Errorf("expected one range check, got %d", len(cfg.Twice.validators))
+	}
+	err := Load(&cfg, Options{Args: []string{"--before=80", "--after=off", "--twice=30%"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Before.Get() != 0.5 {
+		t.Errorf("expected 80%% clamped to 0.5, got %v", cfg.Before.Get())
+	}
+	if cfg.After.Get() != 0 {
+		t.Errorf("expected sentinel as a fraction, got %v", cfg.After.Get())
+	}
+	if cfg.Twice.Get() != 0.3 {
+		t.Errorf("expected 0.3, got %v", cfg.Twice.Get())
+	}
+}
+
+func TestLoad_RequiredErrorHint(t *testing.T) {
+	type dbConfig struct {
+		Password StringParam `cfg:"password"`
+	}
+	cfg := dbConfig{Password: String().Secret().Required().Build()}
+	l := NewLoader(Options{EnvPrefix: "MYAPP"})
+	l.Register("db", &cfg)
+
+	err := l.Load()
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var re *RequiredError
+	if !errors.As(loadErr.Errors[0], &re) {
+		t.Fatalf("expected RequiredError, got %v", loadErr.Errors[0])
+	}
+	if re.EnvVar != "MYAPP_DB_PASSWORD" || re.Flag != "--db.password" {
+		t.Errorf("unexpected hint: %s, %s", re.EnvVar, re.Flag)
+	}
+}
+
+func TestLoad_RequiredInProfile(t *testing.T) {
+	type tlsConfig struct {
+		Cert     StringParam `cfg:"cert"`
+		Password StringParam `cfg:"password"`
+	}
+	load := func(profile string) error {
+		cfg := tlsConfig{
+			Cert:     String().RequiredInProfile("prod", "staging").Build(),
+			Password: String().Secret().RequiredInProfile("prod").Build(),
+		}
+		return Load(&cfg, Options{Profile: profile})
+	}
+
+	if err := load(""); err != nil {
+		t.Errorf("expected no error without a profile, got %v", err)
+	}
+	if err := load("dev"); err != nil {
+		t.Errorf("expected no error in dev, got %v", err)
+	}
+
+	var loadErr *LoadError
+	if err := load("staging"); !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 {
+		t.Fatalf("expected 1 error in staging, got %v", err)
+	}
+	if err := load("prod"); !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected 2 errors in prod, got %v", err)
+	}
+	var re *RequiredError
+	if !errors.As(loadErr.Errors[0], &re) || re.Profile != "prod" {
+		t.Fatalf("expected RequiredError for prod, got %v", loadErr.Errors[0])
+	}
+	if !strings.Contains(re.Error(), `is not set in profile "prod"`) {
+		t.Errorf("expected profile in message, got %q", re.Error())
+	}
+}
+
+func TestLoad_TimeParam(t *testing.T) {
+	type config struct {
+		Cutoff  TimeParam `cfg:"cutoff"`
+		Since   TimeParam `cfg:"since"`
+		Release TimeParam `cfg:"release"`
+		Missing TimeParam `cfg:"missing"`
+	}
+
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	content := "since: 2024-03-01T08:00:00Z\nrelease: 2024-06-30\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config{
+		Cutoff:  Time().Build(),
+		Since:   Time().Build(),
+		Release: Time().Layout("2006-01-02").Build(),
+		Missing: Time().Default(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)).Build(),
+	}
+	err := Load(&cfg, Options{
+		ConfigFile: configFile,
+		Args:       []string{"--cutoff=2024-01-02T15:04:05+01:00"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cutoff := time.Date(2024, 1, 2, 14, 4, 5, 0, time.UTC)
+	if !cfg.Cutoff.Get().Equal(cutoff) {
+		t.Errorf("expected %v, got %v", cutoff, cfg.Cutoff.Get())
+	}
+	if !cfg.Since.Get().Equal(time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected since from YAML timestamp: %v", cfg.Since.Get())
+	}
+	if !cfg.Release.Get().Equal(time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected release: %v", cfg.Release.Get())
+	}
+
+	dump := Dump(&cfg)
+	for _, line := range []string{
+		"cutoff = 2024-01-02T15:04:05+01:00",
+		"release = 2024-06-30",
+		"missing = 2020-01-01T00:00:00Z",
+	} {
+		if !strings.Contains(dump, line) {
+			t.Errorf("expected %q in dump:\n%s", line, dump)
+		}
+	}
+
+	cfg.Cutoff = Time().Build()
+	err = Load(&cfg, Options{Args: []string{"--cutoff=yesterday"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var pe *ParseError
+	if !errors.As(loadErr.Errors[0], &pe) || pe.Key != "cutoff" {
+		t.Errorf("expected ParseError for cutoff, got %v", loadErr.Errors[0])
+	}
+}
+
+func TestLoad_IPParams(t *testing.T) {
+	type config struct {
+		Bind    IPParam    `cfg:"bind"`
+		Peer    IPParam    `cfg:"peer"`
+		Subnet  IPNetParam `cfg:"subnet"`
+		Allowed IPNetParam `cfg:"allowed"`
+	}
+
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	content := "peer: \"::1\"\nsubnet: 10.1.2.3/8\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, allowed, _ := net.ParseCIDR("192.168.0.0/16")
+	cfg := config{
+		Bind:    IP().Build(),
+		Peer:    IP().Build(),
+		Subnet:  IPNet().Build(),
+		Allowed: IPNet().Default(allowed).Build(),
+	}
+	err := Load(&cfg, Options{ConfigFile: configFile, Args: []string{"--bind", "0.0.0.0"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cfg.Bind.Get().Equal(net.IPv4zero) {
+		t.Errorf("expected 0.0.0.0, got %v", cfg.Bind.Get())
+	}
+	if !cfg.Peer.Get().Equal(net.IPv6loopback) {
+		t.Errorf("expected ::1, got %v", cfg.Peer.Get())
+	}
+	if !cfg.Subnet.Get().Contains(net.ParseIP("10.200.0.1")) {
+		t.Errorf("expected subnet to contain 10.200.0.1, got %v", cfg.Subnet.Get())
+	}
+
+	dump := Dump(&cfg)
+	for _, line := range []string{
+		"bind = 0.0.0.0",
+		"peer = ::1",
+		"subnet = 10.0.0.0/8",
+		"allowed = 192.168.0.0/16",
+	} {
+		if !strings.Contains(dump, line) {
+			t.Errorf("expected %q in dump:\n%s", line, dump)
+		}
+	}
+
+	cfg.Bind = IP().Build()
+	cfg.Subnet = IPNet().Build()
+	err = Load(&cfg, Options{Args: []string{"--bind=300.1.1.1", "--subnet=10.0.0.0"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected LoadError with two errors, got %v", err)
+	}
+	for i, key := range []string{"bind", "subnet"} {
+		var pe *ParseError
+		if !errors.As(loadErr.Errors[i], &pe) || pe.Key != key {
+			t.Errorf("expected ParseError for %s, got %v", key, loadErr.Errors[i])
+		}
+	}
+}
+
+func TestLoad_URLParam(t *testing.T) {
+	type config struct {
+		Upstream URLParam `cfg:"upstream"`
+		Database URLParam `cfg:"database"`
+		Missing  URLParam `cfg:"missing"`
+	}
+	newConfig := func() config {
+		return config{
+			Upstream: URL().Schemes("http", "https").Build(),
+			Database: URL().Secret().Build(),
+			Missing:  URL().Schemes("http").Build(),
+		}
+	}
+
+	cfg := newConfig()
+	err := Load(&cfg, Options{Args: []string{
+		"--upstream=HTTPS://api.example.com/v1?x=1",
+		"--database=postgres://user:pass@db:5432/app",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Upstream.Get(); got.Host != "api.example.com" || got.Path != "/v1" {
+		t.Errorf("unexpected upstream: %v", got)
+	}
+
+	dump := Dump(&cfg)
+	for _, line := range []string{
+		"upstream = https://api.example.com/v1?x=1",
+		"database = ****",
+		"missing = <not set>",
+	} {
+		if !strings.Contains(dump, line) {
+			t.Errorf("expected %q in dump:\n%s", line, dump)
+		}
+	}
+
+	cfg = newConfig()
+	err = Load(&cfg, Options{Args: []string{"--upstream=htps://api.example.com", "--database=%zz"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected LoadError with two errors, got %v", err)
+	}
+	var pe *ParseError
+	if !errors.As(loadErr.Errors[0], &pe) || pe.Key != "database" {
+		t.Errorf("expected ParseError for database, got %v", loadErr.Errors[0])
+	}
+	var valErr *ValidationError
+	if !errors.As(loadErr.Errors[1], &valErr) || valErr.Key != "upstream" {
+		t.Errorf("expected ValidationError for upstream, got %v", loadErr.Errors[1])
+	}
+}
+
+func TestLoad_MapStringParam(t *testing.T) {
+	type config struct {
+		Labels  MapStringParam `cfg:"labels"`
+		Tags    MapStringParam `cfg:"tags"`
+		Missing MapStringParam `cfg:"missing"`
+	}
+
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	content := "tags:\n  tier: 1\n  owner: ops\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config{
+		Labels:  MapString().Build(),
+		Tags:    MapString().Build(),
+		Missing: MapString().Default(map[string]string{"a": "1"}).Build(),
+	}
+	err := Load(&cfg, Options{
+		ConfigFile: configFile,
+		Args:       []string{"--labels=team=payments,env=prod,query=a=b"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"env": "prod", "team": "payments", "query": "a=b"}
+	if got := cfg.Labels.Get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := cfg.Tags.Get(); !reflect.DeepEqual(got, map[string]string{"tier": "1", "owner": "ops"}) {
+		t.Errorf("unexpected tags from YAML: %v", got)
+	}
+	cfg.Labels.Get()["env"] = "changed"
+	if cfg.Labels.Get()["env"] != "prod" {
+		t.Error("expected Get to return a copy")
+	}
+
+	expected := "labels = env=prod,query=a=b,team=payments\ntags = owner=ops,tier=1\nmissing = a=1"
+	if got := Dump(&cfg); got != expected {
+		t.Errorf("Dump() =\n%s\nwant:\n%s", got, expected)
+	}
+
+	cfg.Labels = MapString().Build()
+	err = Load(&cfg, Options{Args: []string{"--labels=env=prod,team"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var pe *ParseError
+	if !errors.As(loadErr.Errors[0], &pe) || pe.Value != "team" || pe.Expected != "key=value" {
+		t.Errorf("expected ParseError for the pair without =, got %v", loadErr.Errors[0])
+	}
+}
+
+func TestLoad_MapStringParamFromEnv(t *testing.T) {
+	t.Setenv("APP_LABELS_TEAM", "payments")
+	t.Setenv("APP_LABELS_ENV", "prod")
+	t.Setenv("APP_LABELS", "ignored=1")
+	t.Setenv("PLATFORM_LABELS_REGION", "eu")
+	t.Setenv("APP_TAGS", "tier=1")
+
+	type config struct {
+		Labels MapStringParam `cfg:"labels"`
+		Tags   MapStringParam `cfg:"tags"`
+	}
+	cfg := config{Labels: MapString().Build(), Tags: MapString().Build()}
+	err := Load(&cfg, Options{EnvPrefix: "APP", EnvPrefixes: []string{"PLATFORM"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]string{"team": "payments", "env": "prod"}
+	if got := cfg.Labels.Get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v gathered under the first prefix, got %v", want, got)
+	}
+	if got := cfg.Tags.Get(); !reflect.DeepEqual(got, map[string]string{"tier": "1"}) {
+		t.Errorf("expected tags from the plain variable, got %v", got)
+	}
+}
+
+func TestLoad_DenySource(t *testing.T) {
+	type config struct {
+		Audit   BoolParam   `cfg:"audit"`
+		Region  StringParam `cfg:"region"`
+		Verbose BoolParam   `cfg:"verbose"`
+	}
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte("audit: true\nregion: eu\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_REGION", "us")
+
+	newCfg := func() config {
+		return config{
+			Audit:   Bool().DenySource(SourceCLI).Build(),
+			Region:  String().DenySource(SourceEnv).RejectSource(SourceCLI).Build(),
+			Verbose: Bool().DenySource(SourceCLI).Build(),
+		}
+	}
+
+	cfg := newCfg()
+	err := Load(&cfg, Options{
+		ConfigFile: configFile,
+		EnvPrefix:  "APP",
+		Args:       []string{"--audit=false", "--verbose"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Audit.Get() || cfg.Audit.origin() != "yaml" {
+		t.Errorf("expected CLI ignored and file used, got %v from %s", cfg.Audit.Get(), cfg.Audit.origin())
+	}
+	if cfg.Region.Get() != "eu" {
+		t.Errorf("expected env ignored and file used, got %s", cfg.Region.Get())
+	}
+	if cfg.Verbose.IsSet() {
+		t.Error("expected presence flag from a denied source to be ignored")
+	}
+
+	cfg = newCfg()
+	err = Load(&cfg, Options{ConfigFile: configFile, Args: []string{"--region=ap"}})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	var dse *DeniedSourceError
+	if !errors.As(loadErr.Errors[0], &dse) || dse.Key != "region" || dse.Source != "cli" {
+		t.Errorf("expected DeniedSourceError for region from cli, got %v", loadErr.Errors[0])
+	}
+}
+
+func TestLoad_ShapeMismatch(t *testing.T) {
+	type config struct {
+		Port  IntParam        `cfg:"port"`
+		Name  StringParam     `cfg:"name"`
+		Ports IntListParam    `cfg:"ports"`
+		Tags  StringListParam `cfg:"tags"`
+	}
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := "port: [8080]\nname: [api]\nports: 9090\ntags: a,b\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newCfg := func() config {
+		return config{
+			Port:  Int().Build(),
+			Name:  String().Build(),
+			Ports: IntList().Build(),
+			Tags:  StringList().Build(),
+		}
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		cfg := newCfg()
+		err := Load(&cfg, Options{ConfigFile: configFile})
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) {
+			t.Fatalf("expected LoadError, got %v", err)
+		}
+		want := []struct{ key, expected string }{
+			{"port", "int (got a YAML sequence)"},
+			{"name", "string (got a YAML sequence)"},
+			{"ports", "[]int (got a YAML scalar)"},
+		}
+		if len(loadErr.Errors) != len(want) {
+			t.Fatalf("expected %d errors, got %v", len(want), loadErr.Errors)
+		}
+		for i, w := range want {
+			var pe *ParseError
+			if !errors.As(loadErr.Errors[i], &pe) || pe.Key != w.key || pe.Expected != w.expected {
+				t.Errorf("expected ParseError for %s as %s, got %v", w.key, w.expected, loadErr.Errors[i])
+			}
+		}
+	})
+
+	t.Run("Coerced", func(t *testing.T) {
+		cfg := newCfg()
+		l := NewLoader(Options{
+			ConfigFile:            configFile,
+			UnwrapSingleItemLists: true,
+			WrapScalarsInLists:    true,
+		})
+		l.Register("", &cfg)
+		if err := l.Load(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Port.Get() != 8080 || cfg.Name.Get() != "api" {
+			t.Errorf("expected unwrapped items, got %d and %s", cfg.Port.Get(), cfg.Name.Get())
+		}
+		if !slices.Equal(cfg.Ports.Get(), []int{9090}) {
+			t.Errorf("expected wrapped scalar, got %v", cfg.Ports.Get())
+		}
+		if !slices.Equal(cfg.Tags.Get(), []string{"a", "b"}) {
+			t.Errorf("expected strings still split, got %v", cfg.Tags.Get())
+		}
+		if w := l.Warnings(); len(w) != 2 || w[0].Key != "port" || w[1].Key != "name" {
+			t.Errorf("expected a warning per unwrapped list, got %v", w)
+		}
+	})
+
+	t.Run("TypedSlices", func(t *testing.T) {
+		cfg := newCfg()
+		err := Load(&cfg, Options{ConfigMap: map[string]any{
+			"tags":  []string{"a", "b"},
+			"ports": []int{80, 443},
+		}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(cfg.Tags.Get(), []string{"a", "b"}) ||
+			!slices.Equal(cfg.Ports.Get(), []int{80, 443}) {
+			t.Errorf("expected typed lists accepted, got %v and %v", cfg.Tags.Get(), cfg.Ports.Get())
+		}
+
+		cfg = newCfg()
+		err = Load(&cfg, Options{ConfigMap: map[string]any{"port": []int{8080}}})
+		var loadErr *LoadError
+		if !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 {
+			t.Fatalf("expected one LoadError, got %v", err)
+		}
+		var pe *ParseError
+		if !errors.As(loadErr.Errors[0], &pe) || pe.Expected != "int (got a YAML sequence)" {
+			t.Errorf("expected shape error for port, got %v", loadErr.Errors[0])
+		}
+
+		cfg = newCfg()
+		err = Load(&cfg, Options{
+			ConfigMap:             map[string]any{"port": []int{8080}},
+			UnwrapSingleItemLists: true,
+		})
+		if err != nil || cfg.Port.Get() != 8080 {
+			t.Errorf("expected unwrapped typed list, got %d and %v", cfg.Port.Get(), err)
+		}
+	})
+}
+
+func TestLoad_DotEnvFile(t *testing.T) {
+	type config struct {
+		Host     StringParam   `cfg:"db.host"`
+		Port     IntParam      `cfg:"db.port"`
+		Name     StringParam   `cfg:"db.name"`
+		Password StringParam   `cfg:"db.password"`
+		Timeout  DurationParam `cfg:"db.timeout"`
+	}
+	newCfg := func() config {
+		return config{
+			Host:     String().Build(),
+			Port:     Int().Build(),
+			Name:     String().Build(),
+			Password: String().Build(),
+			Timeout:  Duration().Build(),
+		}
+	}
+
+	dotEnv := filepath.Join(t.TempDir(), ".env")
+	content := `# local development
+MYAPP_DB_HOST=dotenv.db.com
+export MYAPP_DB_PORT=5434 # inline comment
+MYAPP_DB_NAME="my \"db\"\n"
+MYAPP_DB_PASSWORD='p#ss $word'
+
+MYAPP_DB_TIMEOUT=1m
+`
+	if err := os.WriteFile(dotEnv, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MYAPP_DB_TIMEOUT", "2m")
+
+	cfg := newCfg()
+	err := Load(&cfg, Options{EnvPrefix: "MYAPP", DotEnvFile: dotEnv})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host.Get() != "dotenv.db.com" || cfg.Host.origin() != "dotenv" {
+		t.Errorf("expected host from dotenv, got %s from %s", cfg.Host.Get(), cfg.Host.origin())
+	}
+	if cfg.Port.Get() != 5434 {
+		t.Errorf("expected export prefix and comment handled, got %d", cfg.Port.Get())
+	}
+	if cfg.Name.Get() != "my \"db\"\n" {
+		t.Errorf("expected unescaped double-quoted value, got %q", cfg.Name.Get())
+	}
+	if cfg.Password.Get() != "p#ss $word" {
+		t.Errorf("expected literal single-quoted value, got %q", cfg.Password.Get())
+	}
+	if cfg.Timeout.Get() != 2*time.Minute {
+		t.Errorf("expected real env to win over dotenv, got %v", cfg.Timeout.Get())
+	}
+
+	cfg = newCfg()
+	if err := Load(&cfg, Options{DotEnvFile: filepath.Join(t.TempDir(), "missing")}); err != nil {
+		t.Errorf("expected missing file to be ignored, got %v", err)
+	}
+
+	if err := os.WriteFile(dotEnv, []byte("MYAPP_DB_HOST\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var pe *ParseError
+	if err := Load(&cfg, Options{DotEnvFile: dotEnv}); !errors.As(err, &pe) {
+		t.Errorf("expected ParseError for line without =, got %v", err)
+	}
+}
+
+// TestLoader_ConcurrentGet reads values while Load runs; run with -race.
+func TestLoader_ConcurrentGet(t *testing.T) {
+	cfg := newTestConfig()
+	l := NewLoader(Options{})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if h := cfg.DB.Host.Get(); h != "a.db.com" && h != "b.db.com" && h != "localhost" {
+					t.Errorf("unexpected host %q", h)
+					return
+				}
+				_ = cfg.DB.Port.IsSet()
+				_ = l.Dump()
+			}
+		}()
+	}
+
+	for i := range 50 {
+		host := "a.db.com"
+		if i%2 == 1 {
+			host = "b.db.com"
+		}
+		l.opts.Args = []string{"--db.host=" + host}
+		if err := l.Load(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,286 @@
+package confetto
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+)
+
+type subscriber struct {
+	prefix string
+	fn     func(changed []string)
+}
+
+// OnChange registers fn to be called after a reload, that is any successful
+// Load after the first one, if keys under keyPrefix changed value. fn
+// receives the changed keys in sorted order. An empty prefix matches every
+// key.
+func (l *Loader) OnChange(keyPrefix string, fn func(changed []string)) {
+	l.subscribers = append(l.subscribers, subscriber{prefix: keyPrefix, fn: fn})
+}
+
+// notifyChanges compares the loaded values with those of the previous
+// successful Load and calls the subscribers of the changed keys.
+func (l *Loader) notifyChanges(params []Param) {
+	current := make(map[string]string, len(params))
+	for _, p := range params {
+		current[p.key()] = p.stringValue()
+	}
+	previous := l.snapshot
+	l.snapshot = current
+	if previous == nil {
+		return
+	}
+
+	var changed []string
+	for k, v := range current {
+		if old, ok := previous[k]; !ok || old != v {
+			changed = append(changed, k)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	slices.Sort(changed)
+
+	for _, s := range l.subscribers {
+		var mine []string
+		for _, k := range changed {
+			if hasKeyPrefix(k, s.prefix) {
+				mine = append(mine, k)
+			}
+		}
+		if len(mine) > 0 {
+			s.fn(mine)
+		}
+	}
+}
+
+// hasKeyPrefix reports whether key is prefix itself or a key nested under
+// it, so "db" matches "db.host" but not "dbx.host".
+func hasKeyPrefix(key, prefix string) bool {
+	return prefix == "" || key == prefix || strings.HasPrefix(key, prefix+".")
+}
+
+// ReloadOnSignal re-runs Load whenever the process receives sig, e.g.
+// SIGHUP, so values are read again from all sources, including the current
+// environment. Subscribers registered with OnChange are notified as after
+// any Load, and onReload, if not nil, receives the result of each reload.
+// A failed reload leaves the values in place. Reloads run one at a time on
+// a separate goroutine until ctx is done.
+func (l *Loader) ReloadOnSignal(ctx context.Context, sig os.Signal, onReload func(error)) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				err := l.LoadContext(ctx)
+				if onReload != nil {
+					onReload(err)
+				}
+			}
+		}
+	}()
+}
+
+// Watch re-runs Load whenever the config file changes, polling its size and
+// modification time every Options.WatchInterval. The watched files are
+// MergeConfigFiles, ConfigFile, or else every candidate in ConfigPaths, so a
+// file created at a higher-priority path is picked up too. Subscribers
+// registered with OnChange are notified as after any Load, and onReload, if
+// not nil, receives the result of each reload. A reload that fails, e.g.
+// because the file is half-written or holds an invalid value, leaves the
+// values in place.
+//
+// Deleting a watched file does not trigger a reload, so the values loaded
+// from it are kept. When a file is created again at the same path, it is
+// seen as a change and loaded; an editor replacing the file between two
+// polls is seen as a single change.
+//
+// With Options.SecretRotated set, Watch also polls the files the last
+// successful Load read through EnvFileSuffix, such as rotated secrets
+// mounted by an orchestrator. A change to one of them reads only that
+// parameter again, runs its validators and the checks registered with
+// Check, updates it and calls SecretRotated with its key; PostLoad hooks
+// are not run. If the new value is invalid, onReload receives the error
+// and the value is kept.
+//
+// Values may be read with Get while a reload runs. Watching stops when ctx
+// is done.
+func (l *Loader) Watch(ctx context.Context, onReload func(error)) {
+	interval := l.opts.WatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	files := l.watchedFiles()
+	last := statFiles(files)
+	secrets := l.secretStates()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := statFiles(files)
+			changed, deleted := false, false
+			for i := range current {
+				if current[i] != last[i] {
+					changed = true
+					deleted = deleted || (last[i].exists && !current[i].exists)
+				}
+			}
+			last = current
+			if changed && !deleted {
+				err := l.LoadContext(ctx)
+				if onReload != nil {
+					onReload(err)
+				}
+				secrets = l.secretStates()
+				continue
+			}
+			if l.opts.SecretRotated != nil {
+				secrets = l.rotateSecrets(secrets, onReload)
+			}
+		}
+	}()
+}
+
+// secretStates returns the state of the file each key was read from
+// through EnvFileSuffix by the last successful Load.
+func (l *Loader) secretStates() map[string]fileState {
+	l.loadMu.Lock()
+	files := maps.Clone(l.secretFiles)
+	l.loadMu.Unlock()
+	states := make(map[string]fileState, len(files))
+	for key, path := range files {
+		states[key] = statFile(path)
+	}
+	return states
+}
+
+// rotateSecrets reads again the keys whose files changed since last, in
+// sorted order, and returns the current states.
+func (l *Loader) rotateSecrets(last map[string]fileState, onReload func(error)) map[string]fileState {
+	current := l.secretStates()
+	var rotated []string
+	for key, state := range current {
+		if old, ok := last[key]; ok && old != state && state.exists {
+			rotated = append(rotated, key)
+		}
+	}
+	slices.Sort(rotated)
+	for _, key := range rotated {
+		if err := l.rereadSecret(key); err != nil {
+			if onReload != nil {
+				onReload(err)
+			}
+			continue
+		}
+		l.opts.SecretRotated(key)
+	}
+	return current
+}
+
+// rereadSecret reads the value of key again from its file into a copy of
+// its parameter and, if the copy passes the checks of a Load, commits it.
+func (l *Loader) rereadSecret(key string) error {
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
+	path, ok := l.secretFiles[key]
+	if !ok {
+		return nil
+	}
+	params := l.collectAllParams()
+	i := slices.IndexFunc(params, func(p Param) bool { return p.key() == key })
+	if i < 0 {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	opts := l.opts
+	if opts.ListSeparator == "" {
+		opts.ListSeparator = ","
+	}
+	run := &loadRun{opts: opts}
+	p := params[i]
+	c := cloneParam(p)
+	if err := c.setFromString(strings.TrimSpace(string(content)), opts.ListSeparator); err != nil {
+		run.loadErr.Add(maskSecretError(c, err))
+		return &run.loadErr
+	}
+	run.checkParam(c)
+	checked := slices.Clone(params)
+	checked[i] = c
+	l.runChecks(checked, &run.loadErr)
+	if run.loadErr.HasErrors() {
+		return &run.loadErr
+	}
+	l.stateMu.Lock()
+	l.warnings = slices.Concat(l.warnings, run.warnings)
+	l.stateMu.Unlock()
+	p.commit(c)
+	p.publish()
+	l.notifyChanges(params)
+	return nil
+}
+
+// watchedFiles returns the config files Watch polls, with their paths
+// expanded as when they are read.
+func (l *Loader) watchedFiles() []string {
+	switch {
+	case len(l.opts.MergeConfigFiles) > 0:
+		return expandPaths(l.opts.MergeConfigFiles)
+	case l.opts.ConfigFile != "":
+		return []string{l.opts.ConfigFile}
+	}
+	return expandPaths(l.opts.ConfigPaths)
+}
+
+// expandPaths applies expandPath to each of paths.
+func expandPaths(paths []string) []string {
+	files := make([]string, len(paths))
+	for i, p := range paths {
+		files[i] = expandPath(p)
+	}
+	return files
+}
+
+// fileState is what Watch compares to detect a change to a file.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime int64
+}
+
+func statFiles(files []string) []fileState {
+	states := make([]fileState, len(files))
+	for i, f := range files {
+		states[i] = statFile(f)
+	}
+	return states
+}
+
+// statFile returns the state of file, which is the zero fileState if it
+// does not exist.
+func statFile(file string) fileState {
+	info, err := os.Stat(file)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
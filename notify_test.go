@@ -0,0 +1,233 @@
+package confetto
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLoader_OnChange(t *testing.T) {
+	cfg := newTestConfig()
+	args := []string{"--db.host=a.db.com", "--server.addr=:8080"}
+	l := NewLoader(Options{})
+	l.Register("", &cfg)
+
+	var dbChanges, serverChanges, all [][]string
+	l.OnChange("db", func(changed []string) { dbChanges = append(dbChanges, changed) })
+	l.OnChange("server", func(changed []string) { serverChanges = append(serverChanges, changed) })
+	l.OnChange("", func(changed []string) { all = append(all, changed) })
+
+	load := func(a []string) {
+		t.Helper()
+		cfg = newTestConfig()
+		l.opts.Args = a
+		if err := l.Load(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	load(args)
+	if len(all) != 0 {
+		t.Fatalf("expected no callbacks on first load, got %v", all)
+	}
+
+	load(args)
+	if len(all) != 0 {
+		t.Fatalf("expected no callbacks without changes, got %v", all)
+	}
+
+	load([]string{"--db.host=b.db.com", "--db.port=6543", "--server.addr=:8080"})
+	if len(dbChanges) != 1 || !slices.Equal(dbChanges[0], []string{"db.host", "db.port"}) {
+		t.Errorf("unexpected db changes: %v", dbChanges)
+	}
+	if len(serverChanges) != 0 {
+		t.Errorf("expected no server changes, got %v", serverChanges)
+	}
+	if len(all) != 1 {
+		t.Errorf("expected one catch-all callback, got %v", all)
+	}
+}
+
+func TestHasKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key, prefix string
+		want        bool
+	}{
+		{"db.host", "db", true},
+		{"db", "db", true},
+		{"dbx.host", "db", false},
+		{"db.host", "", true},
+		{"db.host", "db.host.x", false},
+	}
+	for _, tt := range tests {
+		if got := hasKeyPrefix(tt.key, tt.prefix); got != tt.want {
+			t.Errorf("hasKeyPrefix(%q, %q) = %v, want %v", tt.key, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestLoader_Watch(t *testing.T) {
+	type config struct {
+		Name StringParam `cfg:"name"`
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string, mtime time.Time) {
+		t.Helper()
+		// write aside and rename, so the watcher never sees a partial file,
+		// with an explicit time, as coarse file system timestamps could hide
+		// a change made within the same tick
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(tmp, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("name: before\n", start)
+
+	cfg := config{Name: String().Build()}
+	l := NewLoader(Options{ConfigFile: path, WatchInterval: 5 * time.Millisecond})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	l.Watch(ctx, func(err error) { reloaded <- err })
+
+	waitReload := func() {
+		t.Helper()
+		select {
+		case err := <-reloaded:
+			if err != nil {
+				t.Fatalf("unexpected reload error: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for reload")
+		}
+	}
+
+	write("name: after\n", start.Add(time.Minute))
+	waitReload()
+	if cfg.Name.Get() != "after" {
+		t.Errorf("expected reloaded value, got %q", cfg.Name.Get())
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloaded:
+		t.Fatalf("expected no reload after deletion, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if cfg.Name.Get() != "after" {
+		t.Errorf("expected value kept after deletion, got %q", cfg.Name.Get())
+	}
+
+	write("name: recreated\n", start.Add(2*time.Minute))
+	waitReload()
+	if cfg.Name.Get() != "recreated" {
+		t.Errorf("expected value from recreated file, got %q", cfg.Name.Get())
+	}
+}
+
+func TestLoader_WatchedFilesExpanded(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("WATCH_DIR", dir)
+	l := NewLoader(Options{MergeConfigFiles: []string{"$WATCH_DIR/base.yaml", "${WATCH_DIR}/prod.yaml"}})
+	want := []string{dir + "/base.yaml", dir + "/prod.yaml"}
+	if got := l.watchedFiles(); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLoader_WatchSecretRotation(t *testing.T) {
+	type config struct {
+		Password StringParam `cfg:"db.password"`
+		Host     StringParam `cfg:"db.host"`
+	}
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "password")
+	write := func(content string, mtime time.Time) {
+		t.Helper()
+		tmp := secret + ".tmp"
+		if err := os.WriteFile(tmp, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(tmp, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, secret); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write("first\n", start)
+	t.Setenv("APP_DB_PASSWORD_FILE", secret)
+	t.Setenv("APP_DB_HOST", "db.example.com")
+
+	rotated := make(chan string, 1)
+	cfg := config{
+		Password: String().Secret().Validate(MinLen(3)).Build(),
+		Host:     String().Build(),
+	}
+	l := NewLoader(Options{
+		EnvPrefix:     "APP",
+		EnvFileSuffix: "_FILE",
+		WatchInterval: 5 * time.Millisecond,
+		SecretRotated: func(key string) { rotated <- key },
+	})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	failed := make(chan error, 1)
+	l.Watch(ctx, func(err error) { failed <- err })
+
+	// the other parameters are not read again
+	t.Setenv("APP_DB_HOST", "other.example.com")
+	write("second\n", start.Add(time.Minute))
+	select {
+	case key := <-rotated:
+		if key != "db.password" {
+			t.Errorf("expected db.password, got %s", key)
+		}
+	case err := <-failed:
+		t.Fatalf("unexpected error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for rotation")
+	}
+	if cfg.Password.Get() != "second" || cfg.Host.Get() != "db.example.com" {
+		t.Errorf("expected only the password updated, got %q and %q",
+			cfg.Password.Get(), cfg.Host.Get())
+	}
+
+	write("x\n", start.Add(2*time.Minute))
+	select {
+	case err := <-failed:
+		if err == nil {
+			t.Error("expected a validation error")
+		}
+	case key := <-rotated:
+		t.Fatalf("expected invalid secret rejected, got rotation of %s", key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the error")
+	}
+	if cfg.Password.Get() != "second" {
+		t.Errorf("expected previous secret kept, got %q", cfg.Password.Get())
+	}
+}
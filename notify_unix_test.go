@@ -0,0 +1,50 @@
+//go:build unix
+
+package confetto
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestLoader_ReloadOnSignal(t *testing.T) {
+	type config struct {
+		Name StringParam `cfg:"name"`
+	}
+	t.Setenv("SIGTEST_NAME", "before")
+
+	cfg := config{Name: String().Build()}
+	l := NewLoader(Options{EnvPrefix: "SIGTEST"})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var changed []string
+	l.OnChange("", func(keys []string) { changed = keys })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 1)
+	l.ReloadOnSignal(ctx, syscall.SIGUSR1, func(err error) { reloaded <- err })
+
+	t.Setenv("SIGTEST_NAME", "after")
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("unexpected reload error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+	if cfg.Name.Get() != "after" {
+		t.Errorf("expected reloaded value, got %q", cfg.Name.Get())
+	}
+	if len(changed) != 1 || changed[0] != "name" {
+		t.Errorf("expected OnChange for name, got %v", changed)
+	}
+}
@@ -1,6 +1,12 @@
 package confetto
 
-import "fmt"
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
 
 // Param is the interface that all parameter types implement.
 type Param interface {
@@ -8,22 +14,75 @@ type Param interface {
 	key() string
 	// setKey sets the configuration key.
 	setKey(k string)
+	// aliases returns alternative keys tried after the canonical key.
+	aliases() []string
+	// deprecatedAliases returns the aliases that are reported with a
+	// warning when used.
+	deprecatedAliases() []string
+	// shortFlags returns the short command line flags, without the dash.
+	shortFlags() []string
 	// setFromString parses and sets the value from a string.
 	setFromString(s string, listSeparator string) error
 	// setFromAny sets the value from an arbitrary type (for YAML).
 	setFromAny(v any, listSeparator string) error
+	// normalize applies all normalizers to the current value and returns
+	// a message for each adjustment made.
+	normalize() []string
 	// validate runs all validators on the current value.
 	validate() error
+	// validateWarn runs all warn-level validators on the current value and
+	// returns the message of each failure.
+	validateWarn() []string
 	// isRequired returns true if this parameter must be set.
 	isRequired() bool
+	// requiredProfiles returns the profiles in which this parameter must be set.
+	requiredProfiles() []string
 	// isSet returns true if the value has been explicitly set.
 	IsSet() bool
+	// Source returns the kind of source that set the value.
+	Source() SourceKind
 	// hasDefault returns true if a default value was configured.
 	hasDefault() bool
 	// isSecret returns true if this parameter contains sensitive data.
 	isSecret() bool
 	// stringValue returns the current value formatted as a string.
 	stringValue() string
+	// getAny returns the current value as an untyped interface.
+	getAny() any
+	// origin returns the name of the source that set the value, if any.
+	origin() string
+	// setOrigin records the name and kind of the source that set the value.
+	setOrigin(name string, kind SourceKind)
+	// publish writes the value to the variable bound with BindTo, if any.
+	publish()
+	// unit returns the unit label of the value, if any.
+	unit() string
+	// description returns the text set with Desc.
+	description() string
+	// useDefault sets the value to the declared default, dropping any
+	// inherited value. It is used on copies made with cloneParam to format
+	// the default.
+	useDefault()
+	// reset makes the parameter unset, with its default as the value and no
+	// source, as before its first Load.
+	reset()
+	// sourceDenied reports whether values from sources of the given kind are
+	// denied, and if so whether they are rejected rather than ignored.
+	sourceDenied(kind SourceKind) (denied, reject bool)
+	// parent returns the key of the parameter whose value is inherited, if any.
+	parent() string
+	// inherit takes the value of q in place of the default until the next
+	// Load, reporting false if q holds a different type. The declared
+	// default is kept.
+	inherit(q Param) bool
+	// envOverride returns the variable name from the env struct tag, if
+	// any, and whether the env prefixes apply to it.
+	envOverride() (name string, prefixed bool)
+	// setEnvOverride records the variable name from the env struct tag.
+	setEnvOverride(name string, prefixed bool)
+	// commit takes the loaded value of q, a copy of this parameter made with
+	// cloneParam, under the write lock.
+	commit(q Param)
 }
 
 // param is the internal generic parameter type that holds configuration for a single value.
@@ -35,51 +94,402 @@ type param[T any] struct {
 	required   bool
 	desc       string
 	k          string
+	src        string
+	srcKind    SourceKind
+	aliasKeys  []string
+	// deprecatedKeys are the aliases that are reported with a warning.
+	deprecatedKeys []string
+	// shortNames are short command line flags, such as "v" for -v.
+	shortNames []string
 	secret     bool
 	validators []func(T) error
+	// setValidators only run when the value is set, not on defaults.
+	setValidators []func(T) error
+	// warnValidators report failures as warnings instead of errors.
+	warnValidators []func(T) error
+	// normalizers adjust the value after parsing; a non-empty message
+	// reports that the value was changed.
+	normalizers []func(T) (T, string)
+	// sentinels maps tokens such as "unlimited" to the value they stand for.
+	sentinels map[string]T
+	// bound is the variable kept in sync with the value, if any.
+	bound *T
+	// clone copies a value that shares memory, such as a slice, a map or a
+	// *big.Rat, so that Get, Set, GetPtr and bound never share the stored
+	// one. It is nil for plain values.
+	clone func(T) T
+	// profiles are the Options.Profile values in which the value is required.
+	profiles []string
+	// unitLabel is the unit of a numeric value, such as "seconds", shown
+	// next to the value in dumps and the type in usage.
+	unitLabel string
+	// deniedSources maps the source kinds the value may not come from to
+	// whether a value from them is rejected (true) or ignored (false).
+	deniedSources map[SourceKind]bool
+	// parentKey is the key of the parameter whose value is inherited as the
+	// default, if any.
+	parentKey string
+	// inherited is set when the value was inherited from parentKey, which
+	// stands in for the default until the next Load.
+	inherited bool
+	// envVar is the variable name from the env struct tag, read instead of
+	// the one derived from the key; envPrefixed applies the env prefixes
+	// to it.
+	envVar      string
+	envPrefixed bool
+	// mu guards the loaded state (value, set, src and the default) against
+	// a concurrent Load. Load parses values into copies made by cloneParam
+	// under the read lock, so the setFrom methods never write to a
+	// parameter that is being read, and takes the write lock only to commit
+	// the result. mu is created by Build, so it is shared by copies of the
+	// parameter and nil for parameters declared without a builder, which
+	// are not locked.
+	mu *sync.RWMutex
 }
 
+func (p *param[T]) rlock() {
+	if p.mu != nil {
+		p.mu.RLock()
+	}
+}
+
+func (p *param[T]) runlock() {
+	if p.mu != nil {
+		p.mu.RUnlock()
+	}
+}
+
+func (p *param[T]) lock() {
+	if p.mu != nil {
+		p.mu.Lock()
+	}
+}
+
+func (p *param[T]) unlock() {
+	if p.mu != nil {
+		p.mu.Unlock()
+	}
+}
+
+// Get returns the value. Lists, maps and pointer values such as *big.Rat
+// are returned as copies, so callers cannot modify the configured value
+// through them.
 func (p *param[T]) Get() T {
-	return p.value
+	p.rlock()
+	defer p.runlock()
+	return p.copyOf(p.value)
+}
+
+// GetOr returns the value, or fallback if the parameter has neither a value
+// from a source nor a default, where Get would return the zero value.
+func (p *param[T]) GetOr(fallback T) T {
+	p.rlock()
+	defer p.runlock()
+	if !p.set && !p.defaulted() {
+		return fallback
+	}
+	return p.copyOf(p.value)
+}
+
+// Set overrides the value, e.g. in tests or from an admin endpoint, and
+// marks it as set, with "Set" as its origin. The validators run first; if
+// one fails the value is left unchanged and its ValidationError returned.
+// Normalizers are not applied. The value is not persisted anywhere: no
+// source is written, and a later Load discards it, resolving the value
+// again from the sources. Like Get, it stores a copy of lists, maps and
+// pointer values.
+func (p *param[T]) Set(v T) error {
+	if err := p.validateValue(v, true); err != nil {
+		return err
+	}
+	p.lock()
+	p.value, p.set, p.src, p.srcKind = p.copyOf(v), true, "Set", SourceSet
+	p.unlock()
+	p.publish()
+	return nil
+}
+
+// GetPtr returns a pointer to a copy of the value, or nil if the value was
+// not explicitly set by a source. Defaults are not reported, so nil always
+// means "not configured" even when the zero value is meaningful.
+func (p *param[T]) GetPtr() *T {
+	p.rlock()
+	defer p.runlock()
+	if !p.set {
+		return nil
+	}
+	v := p.copyOf(p.value)
+	return &v
+}
+
+// copyOf returns v, copied with clone if the type shares memory.
+func (p *param[T]) copyOf(v T) T {
+	if p.clone == nil {
+		return v
+	}
+	return p.clone(v)
 }
 
 func (p *param[T]) IsSet() bool {
+	p.rlock()
+	defer p.runlock()
 	return p.set
 }
 
+// Source returns the kind of source that set the value, SourceSet after
+// Set, or SourceDefault if no source set it, whether or not there is a
+// default.
+func (p *param[T]) Source() SourceKind {
+	p.rlock()
+	defer p.runlock()
+	if !p.set {
+		return SourceDefault
+	}
+	return p.srcKind
+}
+
 func (p *param[T]) key() string {
 	return p.k
 }
 
+// setKey sets the key only if it differs, so that walking a config that was
+// already loaded, as Dump does, does not write to it.
 func (p *param[T]) setKey(k string) {
-	p.k = k
+	if p.k != k {
+		p.k = k
+	}
+}
+
+func (p *param[T]) envOverride() (name string, prefixed bool) {
+	return p.envVar, p.envPrefixed
+}
+
+// setEnvOverride is like setKey, writing only if something changed.
+func (p *param[T]) setEnvOverride(name string, prefixed bool) {
+	if p.envVar != name || p.envPrefixed != prefixed {
+		p.envVar, p.envPrefixed = name, prefixed
+	}
+}
+
+func (p *param[T]) origin() string {
+	p.rlock()
+	defer p.runlock()
+	return p.src
+}
+
+func (p *param[T]) setOrigin(name string, kind SourceKind) {
+	p.src, p.srcKind = name, kind
+}
+
+func (p *param[T]) aliases() []string {
+	return p.aliasKeys
+}
+
+func (p *param[T]) deprecatedAliases() []string {
+	return p.deprecatedKeys
+}
+
+func (p *param[T]) shortFlags() []string {
+	return p.shortNames
 }
 
 func (p *param[T]) isRequired() bool {
 	return p.required
 }
 
+func (p *param[T]) requiredProfiles() []string {
+	return p.profiles
+}
+
 func (p *param[T]) hasDefault() bool {
-	return p.hasDefVal
+	p.rlock()
+	defer p.runlock()
+	return p.defaulted()
+}
+
+// defaulted reports whether the parameter has a declared or inherited
+// default, without locking.
+func (p *param[T]) defaulted() bool {
+	return p.hasDefVal || p.inherited
 }
 
 func (p *param[T]) isSecret() bool {
 	return p.secret
 }
 
+func (p *param[T]) unit() string {
+	return p.unitLabel
+}
+
+func (p *param[T]) description() string {
+	return p.desc
+}
+
+func (p *param[T]) useDefault() {
+	p.value, p.inherited = p.defaultVal, false
+}
+
+func (p *param[T]) reset() {
+	p.value, p.set, p.inherited = p.defaultVal, false, false
+	p.src, p.srcKind = "", 0
+}
+
+func (p *param[T]) sourceDenied(kind SourceKind) (denied, reject bool) {
+	reject, denied = p.deniedSources[kind]
+	return denied, reject
+}
+
+// denySource denies values from sources of the given kind.
+func (p *param[T]) denySource(kind SourceKind, reject bool) {
+	if p.deniedSources == nil {
+		p.deniedSources = make(map[SourceKind]bool)
+	}
+	p.deniedSources[kind] = reject
+}
+
+func (p *param[T]) parent() string {
+	return p.parentKey
+}
+
+func (p *param[T]) inherit(q Param) bool {
+	v, ok := q.getAny().(T)
+	if !ok {
+		return false
+	}
+	p.value = v
+	p.inherited = true
+	return true
+}
+
+// self returns the embedded param, so that commit can reach the state of a
+// copy through the Param interface.
+func (p *param[T]) self() *param[T] {
+	return p
+}
+
+func (p *param[T]) commit(q Param) {
+	c, ok := q.(interface{ self() *param[T] })
+	if !ok {
+		return
+	}
+	loaded := c.self()
+	p.lock()
+	defer p.unlock()
+	p.value, p.set, p.inherited = loaded.value, loaded.set, loaded.inherited
+	p.src, p.srcKind = loaded.src, loaded.srcKind
+}
+
+func (p *param[T]) getAny() any {
+	return p.Get()
+}
+
 func (p *param[T]) stringValue() string {
-	return fmt.Sprintf("%v", p.value)
+	return fmt.Sprintf("%v", p.Get())
+}
+
+// publish writes the value to the bound variable, unless there is none or
+// the value is neither set nor defaulted, so the variable keeps its own
+// initial value until the parameter has one.
+func (p *param[T]) publish() {
+	p.rlock()
+	defer p.runlock()
+	if p.bound != nil && (p.set || p.defaulted()) {
+		*p.bound = p.copyOf(p.value)
+	}
+}
+
+// addSentinel registers a token that parses to the given value.
+func (p *param[T]) addSentinel(token string, v T) {
+	if p.sentinels == nil {
+		p.sentinels = make(map[string]T)
+	}
+	p.sentinels[strings.ToLower(token)] = v
+}
+
+// setSentinel sets the value if s is a registered sentinel token, compared
+// case-insensitively, and reports whether it was.
+func (p *param[T]) setSentinel(s string) bool {
+	v, ok := p.sentinels[strings.ToLower(strings.TrimSpace(s))]
+	if ok {
+		p.value = v
+		p.set = true
+	}
+	return ok
+}
+
+// normalize runs all normalizers on the value and returns their messages.
+// For a secret, the values before and after are replaced with "****".
+func (p *param[T]) normalize() []string {
+	var msgs []string
+	for _, n := range p.normalizers {
+		old := p.value
+		v, msg := n(old)
+		p.value = v
+		if msg == "" {
+			continue
+		}
+		if p.secret {
+			msg = maskValue(maskValue(msg, fmt.Sprintf("%v", old)), fmt.Sprintf("%v", v))
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
 }
 
 func (p *param[T]) validate() error {
-	for _, v := range p.validators {
-		if err := v(p.value); err != nil {
+	return p.validateValue(p.value, p.set)
+}
+
+// validateValue runs all validators on v, including those for set values
+// if set is true. For a secret, the error has "****" as its Value and in
+// place of the value in its Message.
+func (p *param[T]) validateValue(v T, set bool) error {
+	validators := p.validators
+	if set {
+		validators = slices.Concat(p.validators, p.setValidators)
+	}
+	for _, fn := range validators {
+		if err := fn(v); err != nil {
+			if p.secret {
+				return &ValidationError{
+					Key:     p.k,
+					Value:   maskedValue,
+					Message: maskValue(err.Error(), fmt.Sprintf("%v", v)),
+				}
+			}
 			return &ValidationError{
 				Key:     p.k,
-				Value:   p.value,
+				Value:   v,
 				Message: err.Error(),
 			}
 		}
 	}
 	return nil
 }
+
+// clamp returns a normalizer that limits a value to [lo, hi].
+func clamp[T cmp.Ordered](lo, hi T) func(T) (T, string) {
+	return func(v T) (T, string) {
+		c := min(max(v, lo), hi)
+		if c != v {
+			return c, fmt.Sprintf("value %v clamped to %v", v, c)
+		}
+		return v, ""
+	}
+}
+
+// validateWarn runs all warning validators on the value. For a secret,
+// the value is replaced with "****" in the messages.
+func (p *param[T]) validateWarn() []string {
+	var msgs []string
+	for _, v := range p.warnValidators {
+		if err := v(p.value); err != nil {
+			msg := err.Error()
+			if p.secret {
+				msg = maskValue(msg, fmt.Sprintf("%v", p.value))
+			}
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
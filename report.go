@@ -0,0 +1,47 @@
+package confetto
+
+// LoadReport records, for each parameter, where its value came from, to
+// answer "where did this value come from" in production.
+type LoadReport struct {
+	// Params holds one entry per parameter, in registration order.
+	Params []ParamReport
+}
+
+// ParamReport records where the value of a parameter came from.
+type ParamReport struct {
+	Key string
+	// Source is the kind of the source that set the value, or
+	// SourceDefault if no source had the key.
+	Source SourceKind
+	// SourceName is the name of the source that set the value, such as
+	// "env" or "dotenv" for SourceEnv, or empty for SourceDefault.
+	SourceName string
+	// Default reports whether the default was used, as no source had the
+	// key.
+	Default bool
+}
+
+// Param returns the entry for key, reporting false if there is none.
+func (r *LoadReport) Param(key string) (ParamReport, bool) {
+	for _, p := range r.Params {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return ParamReport{}, false
+}
+
+// newLoadReport builds the LoadReport of the given loaded params.
+func newLoadReport(params []Param) *LoadReport {
+	report := &LoadReport{Params: make([]ParamReport, len(params))}
+	for i, p := range params {
+		entry := ParamReport{Key: p.key(), Source: p.Source()}
+		if entry.Source == SourceDefault {
+			entry.Default = p.hasDefault()
+		} else {
+			entry.SourceName = p.origin()
+		}
+		report.Params[i] = entry
+	}
+	return report
+}
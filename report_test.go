@@ -0,0 +1,127 @@
+package confetto
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoader_LoadWithReport(t *testing.T) {
+	t.Setenv("RPT_DB_PORT", "5433")
+
+	cfg := newTestConfig()
+	cfg.DB.MaxConns = IntParam{}
+	l := NewLoader(Options{
+		Args:      []string{"--db.host=cli.db.com"},
+		EnvPrefix: "RPT",
+		ConfigMap: map[string]any{"server": map[string]any{"addr": ":9090"}},
+	})
+	l.Register("", &cfg)
+
+	report, err := l.LoadWithReport()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want ParamReport
+	}{
+		{"db.host", ParamReport{Key: "db.host", Source: SourceCLI, SourceName: "cli"}},
+		{"db.port", ParamReport{Key: "db.port", Source: SourceEnv, SourceName: "env"}},
+		{"server.addr", ParamReport{Key: "server.addr", Source: SourceConfigMap, SourceName: "map"}},
+		{"db.timeout", ParamReport{Key: "db.timeout", Source: SourceDefault, Default: true}},
+		{"db.max_conns", ParamReport{Key: "db.max_conns", Source: SourceDefault}},
+	}
+	for _, tt := range tests {
+		got, ok := report.Param(tt.key)
+		if !ok {
+			t.Errorf("%s: missing from report", tt.key)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.key, tt.want, got)
+		}
+	}
+	if _, ok := report.Param("nope"); ok {
+		t.Error("expected no entry for unknown key")
+	}
+}
+
+func TestLoader_LoadWithReport_SourceLabels(t *testing.T) {
+	t.Setenv("LBL_DB_PORT", "5433")
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overlay := filepath.Join(dir, "override.yaml")
+	files := map[string]string{
+		base:    "db:\n  host: base.db.com\n  timeout: 1m\nserver:\n  addr: \":9090\"\n",
+		overlay: "db:\n  host: prod.db.com\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := newTestConfig()
+	l := NewLoader(Options{
+		MergeConfigFiles: []string{base, overlay},
+		EnvPrefix:        "LBL",
+		Args:             []string{"--server.verbose"},
+		SourceLabels: map[string]string{
+			base:    "base-config",
+			overlay: "override-config",
+			"env":   "cluster-env",
+		},
+	})
+	l.Register("", &cfg)
+
+	report, err := l.LoadWithReport()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string]string{
+		"db.host":        "override-config",
+		"db.timeout":     "base-config",
+		"server.addr":    "base-config",
+		"db.port":        "cluster-env",
+		"server.verbose": "cli",
+	}
+	for key, want := range tests {
+		got, _ := report.Param(key)
+		if got.SourceName != want {
+			t.Errorf("%s: expected source name %q, got %q", key, want, got.SourceName)
+		}
+	}
+	if got, _ := report.Param("db.host"); got.Source != SourceFile {
+		t.Errorf("expected labels to keep the kind, got %v", got.Source)
+	}
+}
+
+func TestLoader_LoadWithReport_ValidationError(t *testing.T) {
+	cfg := newTestConfig()
+	l := NewLoader(Options{Args: []string{"--db.port=0"}})
+	l.Register("", &cfg)
+
+	report, err := l.LoadWithReport()
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	if report == nil {
+		t.Fatal("expected a report along with the LoadError")
+	}
+	if got, _ := report.Param("db.port"); got.Source != SourceCLI {
+		t.Errorf("expected db.port from cli, got %v", got.Source)
+	}
+}
+
+func TestSourceKind_String(t *testing.T) {
+	if got := SourceConfigDir.String(); got != "dir" {
+		t.Errorf("expected dir, got %s", got)
+	}
+	if got := SourceKind(42).String(); got != "SourceKind(42)" {
+		t.Errorf("expected SourceKind(42), got %s", got)
+	}
+}
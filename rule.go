@@ -0,0 +1,99 @@
+package confetto
+
+import (
+	"slices"
+	"time"
+)
+
+// Getter gives typed access to the loaded parameters by key, for Rule.
+// Looking up a key that is not registered, or with a different type,
+// returns the zero value and makes the rule fail with that error.
+type Getter interface {
+	// Has reports whether the key has a value or a default.
+	Has(key string) bool
+	String(key string) string
+	Int(key string) int
+	Bool(key string) bool
+	Float(key string) float64
+	Duration(key string) time.Duration
+	StringList(key string) []string
+}
+
+// Rule registers a named invariant over the whole configuration, checked
+// after everything is loaded. If fn returns an error, Load reports a
+// ValidationError carrying the rule name, e.g.
+// `rule "tls-consistency" failed: cert and key must both be set`.
+func (l *Loader) Rule(name string, fn func(Getter) error) {
+	l.checks = append(l.checks, func(params map[string]Param) error {
+		g := &paramGetter{params: params}
+		err := fn(g)
+		if g.err != nil {
+			err = g.err
+		}
+		if err == nil {
+			return nil
+		}
+		return &ValidationError{Rule: name, Message: err.Error()}
+	})
+}
+
+// paramGetter implements Getter, recording the first lookup error.
+type paramGetter struct {
+	params map[string]Param
+	err    error
+}
+
+func (g *paramGetter) Has(key string) bool {
+	p, ok := g.params[key]
+	if !ok {
+		g.fail(&UnknownKeyError{Key: key})
+		return false
+	}
+	return p.IsSet() || p.hasDefault()
+}
+
+func (g *paramGetter) String(key string) string {
+	return getAs[string](g, key, "string")
+}
+
+func (g *paramGetter) Int(key string) int {
+	return getAs[int](g, key, "int")
+}
+
+func (g *paramGetter) Bool(key string) bool {
+	return getAs[bool](g, key, "bool")
+}
+
+func (g *paramGetter) Float(key string) float64 {
+	return getAs[float64](g, key, "float64")
+}
+
+func (g *paramGetter) Duration(key string) time.Duration {
+	return getAs[time.Duration](g, key, "time.Duration")
+}
+
+func (g *paramGetter) StringList(key string) []string {
+	return slices.Clone(getAs[[]string](g, key, "[]string"))
+}
+
+func (g *paramGetter) fail(err error) {
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+// getAs returns the value of key as a T.
+func getAs[T any](g *paramGetter, key string, typ string) T {
+	var zero T
+	p, ok := g.params[key]
+	if !ok {
+		g.fail(&UnknownKeyError{Key: key})
+		return zero
+	}
+	v, ok := p.getAny().(T)
+	if !ok {
+		g.fail(&ValidationError{Key: key, Value: errorValue(p), Message: "not a " + typ})
+		return zero
+	}
+	return v
+}
@@ -0,0 +1,80 @@
+package confetto
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLoader_Rule(t *testing.T) {
+	type config struct {
+		Enabled BoolParam       `cfg:"tls.enabled"`
+		Cert    StringParam     `cfg:"tls.cert"`
+		Workers IntParam        `cfg:"workers"`
+		Timeout DurationParam   `cfg:"timeout"`
+		Hosts   StringListParam `cfg:"hosts"`
+	}
+	tlsRule := func(g Getter) error {
+		if g.Bool("tls.enabled") && !g.Has("tls.cert") {
+			return errors.New("tls.cert must be set when TLS is enabled")
+		}
+		return nil
+	}
+	load := func(rule func(Getter) error, args ...string) error {
+		cfg := config{
+			Enabled: Bool().Default(false).Build(),
+			Cert:    String().Build(),
+			Workers: Int().Default(4).Build(),
+			Timeout: Duration().Default(time.Second).Build(),
+			Hosts:   StringList().Default([]string{"a", "b"}).Build(),
+		}
+		l := NewLoader(Options{Args: args})
+		l.Register("", &cfg)
+		l.Rule("tls-consistency", rule)
+		return l.Load()
+	}
+
+	if err := load(tlsRule); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var loadErr *LoadError
+	if err := load(tlsRule, "--tls.enabled"); !errors.As(err, &loadErr) {
+		t.Fatalf("expected LoadError, got %v", err)
+	}
+	want := `rule "tls-consistency" failed: tls.cert must be set when TLS is enabled`
+	if loadErr.Errors[0].Error() != want {
+		t.Errorf("unexpected error: %v", loadErr.Errors[0])
+	}
+
+	typed := func(g Getter) error {
+		if n := g.Int("workers") * int(g.Duration("timeout")/time.Second); n != 4 {
+			return fmt.Errorf("got %d", n)
+		}
+		if hosts := g.StringList("hosts"); len(hosts) != 2 || g.String("tls.cert") != "" {
+			return fmt.Errorf("got %v", hosts)
+		}
+		return nil
+	}
+	if err := load(typed); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	tests := map[string]func(Getter) error{
+		"UnknownKey": func(g Getter) error { _ = g.Int("missing"); return nil },
+		"WrongType":  func(g Getter) error { _ = g.Float("workers"); return nil },
+	}
+	for name, rule := range tests {
+		t.Run(name, func(t *testing.T) {
+			var loadErr *LoadError
+			if err := load(rule); !errors.As(err, &loadErr) {
+				t.Fatalf("expected LoadError, got %v", err)
+			}
+			var ve *ValidationError
+			if !errors.As(loadErr.Errors[0], &ve) || ve.Rule != "tls-consistency" {
+				t.Errorf("expected rule ValidationError, got %v", loadErr.Errors[0])
+			}
+		})
+	}
+}
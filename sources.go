@@ -1,7 +1,11 @@
 package confetto
 
 import (
+	"fmt"
+	"maps"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -9,20 +13,97 @@ import (
 
 // source represents a configuration source.
 type source interface {
+	// name returns a short name of the source, used in diagnostics.
+	name() string
 	// get returns the value for a key, or nil if not found.
 	get(key string) any
 }
 
+// SourceKind identifies a kind of configuration source, for
+// Options.SourceOrder, DenySource and LoadReport. Only the main sources,
+// SourceCLI, SourceEnv and SourceFile, can be reordered; ConfigDir and
+// ConfigMap are always consulted after them.
+type SourceKind int
+
+const (
+	// SourceCLI is the command line arguments in Options.Args.
+	SourceCLI SourceKind = iota
+	// SourceEnv is the environment variables, followed by Options.DotEnvFile.
+	SourceEnv
+	// SourceFile is the YAML config file.
+	SourceFile
+	// SourceConfigDir is the directory tree in Options.ConfigDir.
+	SourceConfigDir
+	// SourceConfigMap is the document in Options.ConfigMap.
+	SourceConfigMap
+	// SourceDefault stands for no source at all: the value is the default,
+	// if there is one.
+	SourceDefault
+	// SourceSet is a value given in code with Set.
+	SourceSet
+)
+
+// String returns the lowercase name of the kind, such as "env".
+func (k SourceKind) String() string {
+	switch k {
+	case SourceCLI:
+		return "cli"
+	case SourceEnv:
+		return "env"
+	case SourceFile:
+		return "file"
+	case SourceConfigDir:
+		return "dir"
+	case SourceConfigMap:
+		return "map"
+	case SourceDefault:
+		return "default"
+	case SourceSet:
+		return "set"
+	default:
+		return "SourceKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// defaultSourceOrder is the priority used when Options.SourceOrder is empty.
+//
+//nolint:gochecknoglobals // read-only default
+var defaultSourceOrder = []SourceKind{SourceCLI, SourceEnv, SourceFile}
+
+// presenceSource is implemented by sources that can report whether a key
+// is present independently of its value.
+type presenceSource interface {
+	// has returns true if the key is present in the source.
+	has(key string) bool
+}
+
 // cliSource parses command line arguments.
 type cliSource struct {
 	values map[string]string
+	// positionals are the non-flag tokens found before the "--" terminator.
+	positionals []string
+}
+
+// shortFlag is the parameter a short flag such as -v stands for.
+type shortFlag struct {
+	key     string
+	boolean bool
 }
 
-func newCLISource(args []string) *cliSource {
+func newCLISource(args []string, shorts map[string]shortFlag) *cliSource {
 	s := &cliSource{values: make(map[string]string)}
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
+		if arg == "--" {
+			// everything after the terminator belongs to the program
+			break
+		}
 		if !strings.HasPrefix(arg, "--") {
+			if arg == "-" || !strings.HasPrefix(arg, "-") {
+				s.positionals = append(s.positionals, arg)
+			} else {
+				i += s.parseShort(arg[1:], args[i+1:], shorts)
+			}
 			continue
 		}
 		arg = strings.TrimPrefix(arg, "--")
@@ -48,6 +129,71 @@ func newCLISource(args []string) *cliSource {
 	return s
 }
 
+// parseShort parses a single-dash token: -p=8080 or -p 8080 for a known
+// short flag, -v for a boolean one, and clusters of one-letter flags such
+// as -vx or -vn5 (see parseCluster). Other tokens, such as clusters with
+// unknown letters, are ignored. It returns the number of args from rest
+// consumed as the value.
+func (s *cliSource) parseShort(tok string, rest []string, shorts map[string]shortFlag) int {
+	name, value, hasValue := strings.Cut(tok, "=")
+	f, known := shorts[name]
+	switch {
+	case known && hasValue:
+		s.values[f.key] = value
+	case known && !f.boolean && len(rest) > 0 && !strings.HasPrefix(rest[0], "--"):
+		s.values[f.key] = rest[0]
+		return 1
+	case known:
+		s.values[f.key] = "true"
+	default:
+		return s.parseCluster(tok, rest, shorts)
+	}
+	return 0
+}
+
+// parseCluster parses a token of one-letter short flags. Boolean flags are
+// set to true until the first flag taking a value, which takes the rest of
+// the token, as in -n5 or -vn5, or else the next arg, as in -vn 5. Nothing
+// is set if any letter before the value is not a short flag.
+func (s *cliSource) parseCluster(tok string, rest []string, shorts map[string]shortFlag) int {
+	var bools []string
+	for i, c := range tok {
+		f, ok := shorts[string(c)]
+		if !ok {
+			return 0
+		}
+		if f.boolean {
+			bools = append(bools, f.key)
+			continue
+		}
+		consumed := 0
+		value := strings.TrimPrefix(tok[i+len(string(c)):], "=")
+		switch {
+		case value != "":
+		case len(rest) > 0 && !strings.HasPrefix(rest[0], "--"):
+			value, consumed = rest[0], 1
+		default:
+			value = "true"
+		}
+		s.setTrue(bools)
+		s.values[f.key] = value
+		return consumed
+	}
+	s.setTrue(bools)
+	return 0
+}
+
+// setTrue sets the given keys to "true".
+func (s *cliSource) setTrue(keys []string) {
+	for _, k := range keys {
+		s.values[k] = "true"
+	}
+}
+
+func (s *cliSource) name() string {
+	return "cli"
+}
+
 func (s *cliSource) get(key string) any {
 	if v, ok := s.values[key]; ok {
 		return v
@@ -55,34 +201,323 @@ func (s *cliSource) get(key string) any {
 	return nil
 }
 
-// envSource reads from environment variables.
+// formSource is implemented by sources that read a key in several forms,
+// such as the environment with its file variables and indexed lists.
+type formSource interface {
+	// getForms returns the value for key, the canonical key or an alias
+	// of p, or nil if not found. Lists and maps are only assembled from
+	// several entries for list and map params.
+	getForms(key string, p Param) (any, error)
+}
+
+// envSource reads from environment variables, or from the variables of a
+// dotenv file.
 type envSource struct {
-	prefix string
+	prefixes    []string
+	countSuffix string
+	indexed     bool
+	// fileSuffix names the variables holding the path of a file to read
+	// the value from, such as "_FILE"; empty disables them.
+	fileSuffix string
+	// files maps the keys read through a file variable to the file read.
+	files map[string]string
+	label string
+	// vars holds the variables of a dotenv file; nil means the process
+	// environment.
+	vars map[string]string
 }
 
-func newEnvSource(prefix string) *envSource {
-	return &envSource{prefix: prefix}
+// newEnvSource returns a source trying the given prefixes in priority order.
+// With no prefixes, variable names are not prefixed.
+func newEnvSource(prefixes []string, countSuffix string, indexed bool) *envSource {
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+	return &envSource{
+		prefixes: prefixes, countSuffix: countSuffix, indexed: indexed, label: "env",
+	}
+}
+
+// newDotEnvSource is like newEnvSource but reads the variables from a
+// dotenv file instead of the environment. A missing file yields an empty
+// source.
+func newDotEnvSource(
+	filename string, prefixes []string, countSuffix string, indexed bool,
+) (*envSource, error) {
+	vars, err := readDotEnv(filename)
+	if err != nil {
+		return nil, err
+	}
+	s := newEnvSource(prefixes, countSuffix, indexed)
+	s.label = "dotenv"
+	s.vars = vars
+	return s, nil
+}
+
+func (s *envSource) name() string {
+	return s.label
+}
+
+// lookup returns the value of a variable.
+func (s *envSource) lookup(name string) (string, bool) {
+	if s.vars != nil {
+		v, ok := s.vars[name]
+		return v, ok
+	}
+	return os.LookupEnv(name)
 }
 
 func (s *envSource) get(key string) any {
-	// convert key to env var format: db.host -> DB_HOST
-	envKey := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
-	if s.prefix != "" {
-		envKey = s.prefix + "_" + envKey
+	for _, prefix := range s.prefixes {
+		if v, ok := s.lookup(envName(prefix, key)); ok {
+			return v
+		}
 	}
-	if v, ok := os.LookupEnv(envKey); ok {
-		return v
+	return nil
+}
+
+// getForms tries the prefixes in priority order and, under each, the
+// file variable, the indexed list of a list param or the variables
+// gathered into a map param, and the plain variable, so that a
+// lower-priority prefix never wins over any form of a higher-priority one.
+func (s *envSource) getForms(key string, p Param) (any, error) {
+	list, mapped := isList(p), isMap(p)
+	for _, prefix := range s.prefixes {
+		name := envName(prefix, key)
+		v, path, err := s.getFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			if s.files == nil {
+				s.files = make(map[string]string)
+			}
+			s.files[key] = path
+			return v, nil
+		}
+		if list {
+			items, err := s.getList(key, name)
+			if items != nil || err != nil {
+				return items, err
+			}
+		}
+		if mapped {
+			if m := s.getMap(name); m != nil {
+				return m, nil
+			}
+		}
+		if v, ok := s.lookup(name); ok {
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// getMap gathers the variables named name_*, such as LABELS_TEAM, into a
+// map keyed by the rest of the name in lower case, such as "team". It
+// returns nil if there are none.
+func (s *envSource) getMap(name string) map[string]any {
+	var m map[string]any
+	for k, v := range s.environ() {
+		rest, ok := strings.CutPrefix(k, name+"_")
+		if !ok || rest == "" {
+			continue
+		}
+		if m == nil {
+			m = make(map[string]any)
+		}
+		m[strings.ToLower(rest)] = v
+	}
+	return m
+}
+
+// environ returns all variables of the source.
+func (s *envSource) environ() map[string]string {
+	if s.vars != nil {
+		return s.vars
+	}
+	vars := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			vars[k] = v
+		}
+	}
+	return vars
+}
+
+// getFile returns the trimmed contents and the path of the file named by
+// the variable name with the file suffix, such as DB_PASSWORD_FILE, or nil
+// if file variables are disabled or it is not set.
+func (s *envSource) getFile(name string) (any, string, error) {
+	if s.fileSuffix == "" {
+		return nil, "", nil
+	}
+	name += s.fileSuffix
+	path, ok := s.lookup(name)
+	if !ok {
+		return nil, "", nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimSpace(string(content)), path, nil
+}
+
+// getList reads the list for key from variables indexed after name, e.g.
+// NODES_0 and NODES_1. With a count suffix, the list is bounded by a count
+// variable such as NODES_COUNT=2 and every index below the count must be
+// set. Otherwise, if indexed lists are enabled, the list runs from index 0
+// up to the first missing index. It returns nil if there is no list.
+func (s *envSource) getList(key, name string) ([]any, error) {
+	if s.countSuffix != "" {
+		if countStr, ok := s.lookup(name + s.countSuffix); ok {
+			return s.countedList(key, name, countStr)
+		}
+	}
+	if s.indexed {
+		if items := s.indexedList(name); items != nil {
+			return items, nil
+		}
+	}
+	return nil, nil
+}
+
+// countedList reads count indexed variables named name_0, name_1, ...
+func (s *envSource) countedList(key, name, countStr string) ([]any, error) {
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 0 {
+		return nil, &ParseError{Key: key, Value: countStr, Expected: "list count", Err: err}
+	}
+	items := make([]any, count)
+	for i := range items {
+		itemName := name + "_" + strconv.Itoa(i)
+		v, ok := s.lookup(itemName)
+		if !ok {
+			return nil, &MissingEnvError{Key: key, Var: itemName}
+		}
+		items[i] = v
+	}
+	return items, nil
+}
+
+// indexedList reads variables named name_0, name_1, ... up to the first
+// missing one, returning nil if name_0 is not set.
+func (s *envSource) indexedList(name string) []any {
+	var items []any
+	for i := 0; ; i++ {
+		v, ok := s.lookup(name + "_" + strconv.Itoa(i))
+		if !ok {
+			return items
+		}
+		items = append(items, v)
+	}
+}
+
+// getVar returns the value of a variable named by an env struct tag, tried
+// under each prefix if prefixed, or nil if it is not set.
+func (s *envSource) getVar(name string, prefixed bool) any {
+	prefixes := []string{""}
+	if prefixed {
+		prefixes = s.prefixes
+	}
+	for _, prefix := range prefixes {
+		if v, ok := s.lookup(envName(prefix, name)); ok {
+			return v
+		}
 	}
 	return nil
 }
 
+// varName returns the name of the variable p is read from with the
+// highest-priority prefix.
+func (s *envSource) varName(p Param) string {
+	return paramEnvName(s.prefixes[0], p)
+}
+
+// paramEnvName returns the name of the variable p is read from with the
+// given prefix: the name from its env struct tag, if any, or else the name
+// derived from its key.
+func paramEnvName(prefix string, p Param) string {
+	name, prefixed := p.envOverride()
+	switch {
+	case name == "":
+		return envName(prefix, p.key())
+	case prefixed:
+		return envName(prefix, name)
+	default:
+		return name
+	}
+}
+
+// envName converts a key to its env var name: db.host -> PREFIX_DB_HOST.
+func envName(prefix, key string) string {
+	envKey := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if prefix != "" {
+		envKey = prefix + "_" + envKey
+	}
+	return envKey
+}
+
+// readDotEnv reads the KEY=value lines of a dotenv file. Blank lines and
+// comments are skipped, an "export " prefix is allowed, double-quoted values
+// are unescaped, single-quoted values are taken literally, and unquoted
+// values end at a " #" comment.
+func readDotEnv(filename string) (map[string]string, error) {
+	vars := make(map[string]string)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return vars, nil
+		}
+		return nil, err
+	}
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, &ParseError{
+				Key:      filename + ":" + strconv.Itoa(i+1),
+				Value:    line,
+				Expected: "KEY=value",
+			}
+		}
+		switch {
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, &ParseError{Key: name, Value: value, Expected: "quoted string", Err: err}
+			}
+			value = unquoted
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		default:
+			if j := strings.Index(value, " #"); j >= 0 {
+				value = strings.TrimSpace(value[:j])
+			}
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
 // yamlSource reads from a YAML file.
 type yamlSource struct {
-	data map[string]any
+	label string
+	data  map[string]any
+	raw   []byte
+	// files maps the dotted keys of the leaf values of merged files to the
+	// file each was read from.
+	files map[string]string
 }
 
 func newYAMLSource(filename string) (*yamlSource, error) {
-	s := &yamlSource{data: make(map[string]any)}
+	s := &yamlSource{label: "yaml", data: make(map[string]any)}
 	if filename == "" {
 		return s, nil
 	}
@@ -98,24 +533,204 @@ func newYAMLSource(filename string) (*yamlSource, error) {
 	if err := yaml.Unmarshal(content, &s.data); err != nil {
 		return nil, err
 	}
+	s.raw = content
 
 	return s, nil
 }
 
+// expandEnv expands environment variables in the string values of the
+// source, at any depth.
+func (s *yamlSource) expandEnv() {
+	expandEnvIn(s.data)
+}
+
+// expandEnvIn expands environment variables in v if it is a string, or in
+// the values of v if it is a map or sequence, which are updated in place.
+func expandEnvIn(v any) any {
+	switch val := v.(type) {
+	case string:
+		return os.Expand(val, func(name string) string {
+			if name == "$" {
+				return "$"
+			}
+			return os.Getenv(name)
+		})
+	case map[string]any:
+		for k, item := range val {
+			val[k] = expandEnvIn(item)
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = expandEnvIn(item)
+		}
+	}
+	return v
+}
+
+// newMergedYAMLSource reads the existing files among filenames in order and
+// deep-merges them into one source, later files taking precedence. It also
+// returns the path of the last file read, whose content is kept as raw.
+func newMergedYAMLSource(filenames []string) (*yamlSource, string, error) {
+	merged := &yamlSource{
+		label: "yaml", data: make(map[string]any), files: make(map[string]string),
+	}
+	last := ""
+	for _, f := range filenames {
+		f = expandPath(f)
+		if _, err := os.Stat(f); os.IsNotExist(err) {
+			continue
+		}
+		s, err := newYAMLSource(f)
+		if err != nil {
+			return nil, "", err
+		}
+		mergeMaps(merged.data, s.data)
+		recordFiles(merged.files, "", s.data, f)
+		merged.raw = s.raw
+		last = f
+	}
+	return merged, last, nil
+}
+
+// recordFiles records file as the origin of the leaf values of data, whose
+// keys are under prefix, replacing what earlier files recorded for them.
+func recordFiles(files map[string]string, prefix string, data map[string]any, file string) {
+	for k, v := range data {
+		key := prefix + k
+		if m, ok := v.(map[string]any); ok {
+			delete(files, key)
+			recordFiles(files, key+".", m, file)
+			continue
+		}
+		maps.DeleteFunc(files, func(f, _ string) bool {
+			return strings.HasPrefix(f, key+".")
+		})
+		files[key] = file
+	}
+}
+
+// mergeMaps merges src into dst: nested maps present in both are merged
+// recursively, and any other value in src replaces the one in dst.
+func mergeMaps(dst, src map[string]any) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				mergeMaps(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func (s *yamlSource) name() string {
+	return s.label
+}
+
+// newMapSource creates a source from an already decoded document, using the
+// same dotted-key traversal as a YAML file.
+func newMapSource(data map[string]any) *yamlSource {
+	return &yamlSource{label: "map", data: data}
+}
+
+// newDirSource creates a source from a directory tree such as a mounted
+// Kubernetes ConfigMap: each file is a key holding its contents, and nested
+// directories add key segments, so dir/db/host is read as db.host. Trailing
+// newlines are trimmed, and entries starting with ".." (the bookkeeping
+// directories created by Kubernetes) are skipped. A missing directory
+// yields an empty source.
+func newDirSource(dir string) (*yamlSource, error) {
+	data, err := readDirTree(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &yamlSource{label: "dir", data: make(map[string]any)}, nil
+		}
+		return nil, err
+	}
+	return &yamlSource{label: "dir", data: data}, nil
+}
+
+// readDirTree reads dir into a nested map keyed by file and directory names.
+// Symlinks are followed, as ConfigMap keys are links into the ..data
+// directory.
+func readDirTree(dir string) (map[string]any, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]any, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "..") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			if data[e.Name()], err = readDirTree(path); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data[e.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+	return data, nil
+}
+
 func (s *yamlSource) get(key string) any {
+	v, _ := s.lookup(key)
+	return v
+}
+
+// has reports whether the key path exists in the YAML document, regardless
+// of its value. A key with no value (e.g. "beta:") exists, as does a plain
+// string item of a sequence found at the parent path (e.g. "- beta").
+func (s *yamlSource) has(key string) bool {
+	if _, ok := s.lookup(key); ok {
+		return true
+	}
+	parent, last := "", key
+	if idx := strings.LastIndex(key, "."); idx != -1 {
+		parent, last = key[:idx], key[idx+1:]
+	}
+	var seq any = s.data
+	if parent != "" {
+		seq, _ = s.lookup(parent)
+	}
+	items, ok := seq.([]any)
+	if !ok {
+		return false
+	}
+	for _, item := range items {
+		if str, ok := item.(string); ok && str == last {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup walks the dotted key path and returns the value found there and
+// whether the path exists at all.
+func (s *yamlSource) lookup(key string) (any, bool) {
 	parts := strings.Split(key, ".")
 	var current any = s.data
 
 	for _, part := range parts {
 		m, ok := current.(map[string]any)
 		if !ok {
-			return nil
+			return nil, false
 		}
 		current, ok = m[part]
 		if !ok {
-			return nil
+			return nil, false
 		}
 	}
 
-	return current
+	return current, true
 }
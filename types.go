@@ -2,14 +2,24 @@ package confetto
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 // StringParam holds a string configuration value.
 type StringParam struct {
 	param[string]
+	document         bool
+	relativeToConfig bool
 }
 
 //nolint:unparam // error is always nil but signature must match other param types
@@ -19,11 +29,23 @@ func (p *StringParam) setFromString(s string, _ string) error {
 	return nil
 }
 
-//nolint:unparam // error is always nil but signature must match other param types
 func (p *StringParam) setFromAny(v any, _ string) error {
 	switch val := v.(type) {
 	case string:
 		p.value = val
+	case map[string]any, []any:
+		if !p.document {
+			return &ParseError{
+				Key:      p.k,
+				Value:    fmt.Sprintf("%v", val),
+				Expected: "string (got a YAML " + yamlShape(val) + ")",
+			}
+		}
+		out, err := yaml.Marshal(val)
+		if err != nil {
+			return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", val), Expected: "string", Err: err}
+		}
+		p.value = string(out)
 	default:
 		p.value = fmt.Sprintf("%v", val)
 	}
@@ -31,12 +53,23 @@ func (p *StringParam) setFromAny(v any, _ string) error {
 	return nil
 }
 
+// yamlShape names the kind of a decoded YAML collection node.
+func yamlShape(v any) string {
+	if _, ok := v.([]any); ok {
+		return "sequence"
+	}
+	return "mapping"
+}
+
 // IntParam holds an int configuration value.
 type IntParam struct {
 	param[int]
 }
 
 func (p *IntParam) setFromString(s string, _ string) error {
+	if p.setSentinel(s) {
+		return nil
+	}
 	v, err := strconv.Atoi(s)
 	if err != nil {
 		return &ParseError{Key: p.k, Value: s, Expected: "int", Err: err}
@@ -66,10 +99,18 @@ func (p *IntParam) setFromAny(v any, _ string) error {
 // BoolParam holds a bool configuration value.
 type BoolParam struct {
 	param[bool]
+	presence bool
+	lenient  bool
+}
+
+// fromPresence reports whether the mere presence of the key in the config
+// file sets this parameter to true.
+func (p *BoolParam) fromPresence() bool {
+	return p.presence
 }
 
 func (p *BoolParam) setFromString(s string, _ string) error {
-	v, err := strconv.ParseBool(s)
+	v, err := parseBool(s, p.lenient)
 	if err != nil {
 		return &ParseError{Key: p.k, Value: s, Expected: "bool", Err: err}
 	}
@@ -91,59 +132,126 @@ func (p *BoolParam) setFromAny(v any, _ string) error {
 	return nil
 }
 
+// parseBool parses a bool as strconv.ParseBool does. In lenient mode it also
+// accepts yes/no, y/n and on/off in any case.
+func parseBool(s string, lenient bool) (bool, error) {
+	if lenient {
+		switch strings.ToLower(strings.TrimSpace(s)) {
+		case "yes", "y", "on":
+			return true, nil
+		case "no", "n", "off":
+			return false, nil
+		}
+	}
+	return strconv.ParseBool(s)
+}
+
 // FloatParam holds a float64 configuration value.
 type FloatParam struct {
 	param[float64]
+	// percent makes values read as percentages and stored as fractions.
+	percent bool
 }
 
 func (p *FloatParam) setFromString(s string, _ string) error {
-	v, err := strconv.ParseFloat(s, 64)
+	if p.setSentinel(s) {
+		return nil
+	}
+	num := s
+	if p.percent {
+		num = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	}
+	v, err := strconv.ParseFloat(num, 64)
 	if err != nil {
 		return &ParseError{Key: p.k, Value: s, Expected: "float64", Err: err}
 	}
-	p.value = v
+	p.value = p.scaled(v)
 	p.set = true
 	return nil
 }
 
 func (p *FloatParam) setFromAny(v any, _ string) error {
+	var f float64
 	switch val := v.(type) {
 	case float64:
-		p.value = val
+		f = val
 	case float32:
-		p.value = float64(val)
+		f = float64(val)
 	case int:
-		p.value = float64(val)
+		f = float64(val)
 	case int64:
-		p.value = float64(val)
+		f = float64(val)
 	case string:
 		return p.setFromString(val, "")
 	default:
 		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "float64"}
 	}
+	p.value = p.scaled(f)
 	p.set = true
 	return nil
 }
 
+// scaled converts a percentage to a fraction if the parameter reads
+// percentages, and returns v unchanged otherwise.
+func (p *FloatParam) scaled(v float64) float64 {
+	if p.percent {
+		return v / 100
+	}
+	return v
+}
+
 // DurationParam holds a time.Duration configuration value.
 type DurationParam struct {
 	param[time.Duration]
+	withinDeadline bool
+	// bareUnit is the unit of numbers given without one, such as "30";
+	// zero means time.Second.
+	bareUnit time.Duration
 }
 
+// setFromString parses a duration such as "5s" or "2m", or a bare number
+// such as "30" or "1.5", which is taken in the unit set with
+// DurationBuilder.Unit.
 func (p *DurationParam) setFromString(s string, _ string) error {
+	if p.setSentinel(s) {
+		return nil
+	}
 	v, err := time.ParseDuration(s)
 	if err != nil {
-		return &ParseError{Key: p.k, Value: s, Expected: "duration", Err: err}
+		var ok bool
+		if v, ok = p.parseBare(s); !ok {
+			return &ParseError{Key: p.k, Value: s, Expected: "duration", Err: err}
+		}
 	}
 	p.value = v
 	p.set = true
 	return nil
 }
 
+// parseBare parses a number without a unit as a count of p.bareUnit,
+// reporting false if s is not a number or the duration overflows.
+func (p *DurationParam) parseBare(s string) (time.Duration, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	unit := p.bareUnit
+	if unit == 0 {
+		unit = time.Second
+	}
+	d := f * float64(unit)
+	if math.IsNaN(d) || d >= math.MaxInt64 || d < math.MinInt64 {
+		return 0, false
+	}
+	return time.Duration(d), true
+}
+
 func (p *DurationParam) setFromAny(v any, _ string) error {
 	switch val := v.(type) {
 	case string:
 		return p.setFromString(val, "")
+	case time.Duration:
+		p.value = val
 	case int:
 		p.value = time.Duration(val)
 	case int64:
@@ -157,6 +265,164 @@ func (p *DurationParam) setFromAny(v any, _ string) error {
 	return nil
 }
 
+// ByteSizeParam holds a size in bytes, given as a plain number of bytes or
+// with a decimal (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB) suffix,
+// such as "10MB" or "1.5GiB". Suffixes are case-insensitive.
+type ByteSizeParam struct {
+	param[int64]
+}
+
+func (p *ByteSizeParam) setFromString(s string, _ string) error {
+	if p.setSentinel(s) {
+		return nil
+	}
+	v, ok := parseByteSize(s)
+	if !ok {
+		return &ParseError{Key: p.k, Value: s, Expected: "byte size"}
+	}
+	p.value = v
+	p.set = true
+	return nil
+}
+
+func (p *ByteSizeParam) setFromAny(v any, _ string) error {
+	switch val := v.(type) {
+	case int:
+		if val < 0 {
+			return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "byte size"}
+		}
+		p.value = int64(val)
+	case int64:
+		if val < 0 {
+			return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "byte size"}
+		}
+		p.value = val
+	case float64:
+		n, ok := wholeBytes(val)
+		if !ok {
+			return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "byte size"}
+		}
+		p.value = n
+	case string:
+		return p.setFromString(val, "")
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "byte size"}
+	}
+	p.set = true
+	return nil
+}
+
+func (p *ByteSizeParam) stringValue() string {
+	return formatByteSize(p.Get())
+}
+
+// byteUnits are the byte size suffixes, largest first, so that
+// parseByteSize tries "KiB" before "B" and formatByteSize picks the largest
+// exact unit.
+var byteUnits = []struct { //nolint:gochecknoglobals // read-only table
+	suffix string
+	size   int64
+}{
+	{"TiB", 1 << 40}, {"TB", 1e12},
+	{"GiB", 1 << 30}, {"GB", 1e9},
+	{"MiB", 1 << 20}, {"MB", 1e6},
+	{"KiB", 1 << 10}, {"KB", 1e3},
+	{"B", 1},
+}
+
+// parseByteSize parses a byte size such as "512", "10MB" or "1.5 GiB",
+// reporting false unless it is a whole, non-negative number of bytes.
+func parseByteSize(s string) (int64, bool) {
+	num := strings.TrimSpace(s)
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if len(num) > len(u.suffix) && strings.EqualFold(num[len(num)-len(u.suffix):], u.suffix) {
+			num, unit = strings.TrimSpace(num[:len(num)-len(u.suffix)]), u.size
+			break
+		}
+	}
+	// big.Rat keeps decimals such as "4.1" exact, where a float64 would
+	// turn 4.1MB into a fraction of a byte. It also reads "a/b", which is
+	// not a byte size.
+	r, ok := new(big.Rat).SetString(num)
+	if !ok || strings.Contains(num, "/") {
+		return 0, false
+	}
+	r.Mul(r, new(big.Rat).SetInt64(unit))
+	if r.Sign() < 0 || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+	return r.Num().Int64(), true
+}
+
+// wholeBytes converts f to int64, reporting false if it is negative, not a
+// whole number or too large.
+func wholeBytes(f float64) (int64, bool) {
+	if f < 0 || f >= math.MaxInt64 || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// formatByteSize formats n with the largest suffix that divides it exactly,
+// such as "10MB" or "4KiB", so that parseByteSize reads it back unchanged.
+func formatByteSize(n int64) string {
+	for _, u := range byteUnits {
+		if n != 0 && n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// TimeParam holds an absolute time.Time configuration value.
+type TimeParam struct {
+	param[time.Time]
+	layout string
+	// future and past require the value to be after or before the time of
+	// the Load, as given by Options.Now.
+	future bool
+	past   bool
+}
+
+// timeLayout returns the layout used to parse and format the value.
+func (p *TimeParam) timeLayout() string {
+	if p.layout == "" {
+		return time.RFC3339
+	}
+	return p.layout
+}
+
+func (p *TimeParam) setFromString(s string, _ string) error {
+	v, err := time.Parse(p.timeLayout(), strings.TrimSpace(s))
+	if err != nil {
+		return &ParseError{Key: p.k, Value: s, Expected: "time (" + p.timeLayout() + ")", Err: err}
+	}
+	p.value = v
+	p.set = true
+	return nil
+}
+
+// setFromAny also accepts a time.Time, as YAML timestamps are decoded
+// natively.
+func (p *TimeParam) setFromAny(v any, _ string) error {
+	switch val := v.(type) {
+	case time.Time:
+		p.value = val
+	case string:
+		return p.setFromString(val, "")
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "time"}
+	}
+	p.set = true
+	return nil
+}
+
+// stringValue formats the time with the configured layout.
+func (p *TimeParam) stringValue() string {
+	return p.Get().Format(p.timeLayout())
+}
+
 // StringListParam holds a []string configuration value.
 type StringListParam struct {
 	param[[]string]
@@ -226,6 +492,12 @@ func (p *IntListParam) setFromAny(v any, sep string) error {
 				p.value[i] = int(n)
 			case float64:
 				p.value[i] = int(n)
+			case string:
+				parsed, err := strconv.Atoi(strings.TrimSpace(n))
+				if err != nil {
+					return &ParseError{Key: p.k, Value: n, Expected: "int", Err: err}
+				}
+				p.value[i] = parsed
 			default:
 				return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", item), Expected: "int"}
 			}
@@ -244,6 +516,7 @@ func (p *IntListParam) setFromAny(v any, sep string) error {
 // BoolListParam holds a []bool configuration value.
 type BoolListParam struct {
 	param[[]bool]
+	lenient bool
 }
 
 func (p *BoolListParam) setFromString(s string, sep string) error {
@@ -255,7 +528,7 @@ func (p *BoolListParam) setFromString(s string, sep string) error {
 	parts := strings.Split(s, sep)
 	p.value = make([]bool, len(parts))
 	for i, part := range parts {
-		v, err := strconv.ParseBool(strings.TrimSpace(part))
+		v, err := parseBool(strings.TrimSpace(part), p.lenient)
 		if err != nil {
 			return &ParseError{Key: p.k, Value: part, Expected: "bool", Err: err}
 		}
@@ -273,6 +546,12 @@ func (p *BoolListParam) setFromAny(v any, sep string) error {
 			switch b := item.(type) {
 			case bool:
 				p.value[i] = b
+			case string:
+				parsed, err := parseBool(strings.TrimSpace(b), p.lenient)
+				if err != nil {
+					return &ParseError{Key: p.k, Value: b, Expected: "bool", Err: err}
+				}
+				p.value[i] = parsed
 			default:
 				return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", item), Expected: "bool"}
 			}
@@ -326,6 +605,12 @@ func (p *FloatListParam) setFromAny(v any, sep string) error {
 				p.value[i] = float64(n)
 			case int64:
 				p.value[i] = float64(n)
+			case string:
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+				if err != nil {
+					return &ParseError{Key: p.k, Value: n, Expected: "float64", Err: err}
+				}
+				p.value[i] = parsed
 			default:
 				return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", item), Expected: "float64"}
 			}
@@ -397,3 +682,328 @@ func (p *DurationListParam) setFromAny(v any, sep string) error {
 	p.set = true
 	return nil
 }
+
+// NestedStringListParam holds a [][]string configuration value: a list whose
+// items are themselves split by an inner separator, so that "a:1,b:2"
+// becomes [[a 1] [b 2]]. This is a compact single-line encoding for CLI and
+// ENV; in YAML, items can be sequences or strings split the same way.
+type NestedStringListParam struct {
+	param[[][]string]
+	innerSep string
+}
+
+// cloneNested returns a deep copy of v, or nil if v is nil.
+func cloneNested(v [][]string) [][]string {
+	if v == nil {
+		return nil
+	}
+	out := make([][]string, len(v))
+	for i, item := range v {
+		out[i] = slices.Clone(item)
+	}
+	return out
+}
+
+func (p *NestedStringListParam) innerSeparator() string {
+	if p.innerSep == "" {
+		return ":"
+	}
+	return p.innerSep
+}
+
+//nolint:unparam // error is always nil but signature must match other param types
+func (p *NestedStringListParam) setFromString(s string, sep string) error {
+	p.value = [][]string{}
+	if s != "" {
+		for _, item := range strings.Split(s, sep) {
+			p.value = append(p.value, strings.Split(item, p.innerSeparator()))
+		}
+	}
+	p.set = true
+	return nil
+}
+
+func (p *NestedStringListParam) setFromAny(v any, sep string) error {
+	switch val := v.(type) {
+	case []any:
+		p.value = make([][]string, len(val))
+		for i, item := range val {
+			switch inner := item.(type) {
+			case string:
+				p.value[i] = strings.Split(inner, p.innerSeparator())
+			case []any:
+				p.value[i] = make([]string, len(inner))
+				for j, field := range inner {
+					p.value[i][j] = fmt.Sprintf("%v", field)
+				}
+			default:
+				return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", item), Expected: "[]string"}
+			}
+		}
+	case [][]string:
+		p.value = val
+	case string:
+		return p.setFromString(val, sep)
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "[][]string"}
+	}
+	p.set = true
+	return nil
+}
+
+// MapStringParam holds a map[string]string configuration value, such as
+// labels. In strings it is written as key=value pairs separated by the list
+// separator: env=prod,team=payments.
+type MapStringParam struct {
+	param[map[string]string]
+}
+
+func (p *MapStringParam) setFromString(s string, sep string) error {
+	m := make(map[string]string)
+	if s != "" {
+		for _, pair := range strings.Split(s, sep) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return &ParseError{Key: p.k, Value: pair, Expected: "key=value"}
+			}
+			m[k] = v
+		}
+	}
+	p.value = m
+	p.set = true
+	return nil
+}
+
+func (p *MapStringParam) setFromAny(v any, sep string) error {
+	switch val := v.(type) {
+	case map[string]any:
+		p.value = make(map[string]string, len(val))
+		for k, item := range val {
+			p.value[k] = fmt.Sprintf("%v", item)
+		}
+	case map[string]string:
+		p.value = val
+	case string:
+		return p.setFromString(val, sep)
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "map[string]string"}
+	}
+	p.set = true
+	return nil
+}
+
+// stringValue formats the map as key=value pairs sorted by key.
+func (p *MapStringParam) stringValue() string {
+	value := p.param.Get()
+	pairs := make([]string, 0, len(value))
+	for _, k := range slices.Sorted(maps.Keys(value)) {
+		pairs = append(pairs, k+"="+value[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// DecimalParam holds an exact decimal configuration value, for amounts that
+// must not be subject to float64 rounding.
+type DecimalParam struct {
+	param[*big.Rat]
+	scale  int
+	scaled bool
+}
+
+func (p *DecimalParam) setFromString(s string, _ string) error {
+	v, digits, ok := parseDecimal(strings.TrimSpace(s))
+	if !ok {
+		return &ParseError{Key: p.k, Value: s, Expected: "decimal"}
+	}
+	if p.scaled && digits > p.scale {
+		return &ParseError{
+			Key:      p.k,
+			Value:    s,
+			Expected: fmt.Sprintf("decimal with at most %d fractional digits", p.scale),
+		}
+	}
+	p.value = v
+	p.set = true
+	return nil
+}
+
+func (p *DecimalParam) setFromAny(v any, _ string) error {
+	switch val := v.(type) {
+	case string:
+		return p.setFromString(val, "")
+	case int:
+		return p.setFromString(strconv.Itoa(val), "")
+	case int64:
+		return p.setFromString(strconv.FormatInt(val, 10), "")
+	case float64:
+		// YAML floats are formatted with the shortest exact representation;
+		// quote amounts in YAML to avoid any float intermediate.
+		return p.setFromString(strconv.FormatFloat(val, 'f', -1, 64), "")
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "decimal"}
+	}
+}
+
+func (p *DecimalParam) stringValue() string {
+	value := p.Get()
+	if value == nil {
+		return "<nil>"
+	}
+	if p.scaled {
+		return value.FloatString(p.scale)
+	}
+	return decimalString(value)
+}
+
+// parseDecimal parses a plain decimal number such as "-12.50" and returns it
+// along with its number of fractional digits. Exponents and fractions like
+// "1/3" are rejected.
+func parseDecimal(s string) (*big.Rat, int, bool) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 || digits == "" {
+		return nil, 0, false
+	}
+	intPart, fracPart, hasDot := strings.Cut(digits, ".")
+	if intPart == "" && fracPart == "" || hasDot && fracPart == "" {
+		return nil, 0, false
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return nil, 0, false
+		}
+	}
+	v, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, 0, false
+	}
+	return v, len(fracPart), true
+}
+
+// decimalString formats r with the fewest fractional digits that represent
+// it exactly.
+func decimalString(r *big.Rat) string {
+	const maxDigits = 64
+	ten := big.NewRat(10, 1)
+	n := 0
+	for d := new(big.Rat).Set(r); !d.IsInt() && n < maxDigits; n++ {
+		d.Mul(d, ten)
+	}
+	return r.FloatString(n)
+}
+
+// cloneRat returns a copy of r, or nil if r is nil.
+func cloneRat(r *big.Rat) *big.Rat {
+	if r == nil {
+		return nil
+	}
+	return new(big.Rat).Set(r)
+}
+
+// IPParam holds a net.IP configuration value, either IPv4 or IPv6.
+type IPParam struct {
+	param[net.IP]
+}
+
+func (p *IPParam) setFromString(s string, _ string) error {
+	v := net.ParseIP(strings.TrimSpace(s))
+	if v == nil {
+		return &ParseError{Key: p.k, Value: s, Expected: "IP address"}
+	}
+	p.value = v
+	p.set = true
+	return nil
+}
+
+// setFromAny also accepts a net.IP, as from Options.ConfigMap.
+func (p *IPParam) setFromAny(v any, _ string) error {
+	switch val := v.(type) {
+	case net.IP:
+		p.value = slices.Clone(val)
+	case string:
+		return p.setFromString(val, "")
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "IP address"}
+	}
+	p.set = true
+	return nil
+}
+
+// IPNetParam holds a *net.IPNet configuration value, a CIDR block such as
+// 10.0.0.0/8.
+type IPNetParam struct {
+	param[*net.IPNet]
+}
+
+// setFromString parses a CIDR block. The address is masked to the network,
+// so 10.1.2.3/8 is read as 10.0.0.0/8.
+func (p *IPNetParam) setFromString(s string, _ string) error {
+	_, v, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return &ParseError{Key: p.k, Value: s, Expected: "CIDR block", Err: err}
+	}
+	p.value = v
+	p.set = true
+	return nil
+}
+
+// setFromAny also accepts a *net.IPNet, as from Options.ConfigMap.
+func (p *IPNetParam) setFromAny(v any, _ string) error {
+	switch val := v.(type) {
+	case *net.IPNet:
+		p.value = cloneIPNet(val)
+	case string:
+		return p.setFromString(val, "")
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "CIDR block"}
+	}
+	p.set = true
+	return nil
+}
+
+// cloneIPNet returns a copy of n, or nil if n is nil.
+func cloneIPNet(n *net.IPNet) *net.IPNet {
+	if n == nil {
+		return nil
+	}
+	return &net.IPNet{IP: slices.Clone(n.IP), Mask: slices.Clone(n.Mask)}
+}
+
+// URLParam holds a *url.URL configuration value, such as an upstream
+// endpoint.
+type URLParam struct {
+	param[*url.URL]
+}
+
+func (p *URLParam) setFromString(s string, _ string) error {
+	v, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return &ParseError{Key: p.k, Value: s, Expected: "URL", Err: err}
+	}
+	p.value = v
+	p.set = true
+	return nil
+}
+
+// setFromAny also accepts a *url.URL, as from Options.ConfigMap.
+func (p *URLParam) setFromAny(v any, _ string) error {
+	switch val := v.(type) {
+	case *url.URL:
+		p.value = cloneURL(val)
+	case string:
+		return p.setFromString(val, "")
+	default:
+		return &ParseError{Key: p.k, Value: fmt.Sprintf("%v", v), Expected: "URL"}
+	}
+	p.set = true
+	return nil
+}
+
+// cloneURL returns a copy of u, or nil if u is nil. The Userinfo is shared,
+// as it cannot be modified.
+func cloneURL(u *url.URL) *url.URL {
+	if u == nil {
+		return nil
+	}
+	c := *u
+	return &c
+}
@@ -1,6 +1,14 @@
 package confetto
 
-import "testing"
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestLoad_ParamGetBeforeSet(t *testing.T) {
 	t.Run("StringParam", func(t *testing.T) {
@@ -70,3 +78,364 @@ func TestLoad_IsSetBehavior(t *testing.T) {
 		t.Error("expected IsSet() == true after Load with explicit value")
 	}
 }
+
+func TestParam_GetPtr(t *testing.T) {
+	type ptrConfig struct {
+		Timeout IntParam `cfg:"timeout"`
+		Retries IntParam `cfg:"retries"`
+	}
+
+	cfg := ptrConfig{
+		Timeout: Int().Build(),
+		Retries: Int().Default(3).Build(),
+	}
+
+	err := Load(&cfg, Options{Args: []string{"--timeout=0"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ptr := cfg.Timeout.GetPtr()
+	if ptr == nil {
+		t.Fatal("expected non-nil pointer for explicitly set zero value")
+	}
+	if *ptr != 0 {
+		t.Errorf("expected 0, got %d", *ptr)
+	}
+
+	if cfg.Retries.GetPtr() != nil {
+		t.Error("expected nil pointer for default-only value")
+	}
+}
+
+func TestParam_GetPtrAndBindToCopy(t *testing.T) {
+	var bound []string
+	type listConfig struct {
+		Hosts StringListParam       `cfg:"hosts"`
+		Tags  MapStringParam        `cfg:"tags"`
+		Rows  NestedStringListParam `cfg:"rows"`
+	}
+
+	cfg := listConfig{
+		Hosts: StringList().BindTo(&bound).Build(),
+		Tags:  MapString().Build(),
+		Rows:  NestedStringList().Build(),
+	}
+	err := Load(&cfg, Options{Args: []string{"--hosts=a,b", "--tags=k=v", "--rows=a:b,c"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	(*cfg.Hosts.GetPtr())[0] = "x"
+	bound[1] = "y"
+	(*cfg.Tags.GetPtr())["k"] = "x"
+	(*cfg.Rows.GetPtr())[0][0] = "x"
+	if got := cfg.Hosts.Get(); got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected [a b], got %v", got)
+	}
+	if got := cfg.Tags.Get(); got["k"] != "v" {
+		t.Errorf("expected k=v, got %v", got)
+	}
+	if got := cfg.Rows.Get(); got[0][0] != "a" {
+		t.Errorf("expected a, got %v", got)
+	}
+
+	if err := cfg.Hosts.Set([]string{"c"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bound[0] = "z"
+	if got := cfg.Hosts.Get(); got[0] != "c" {
+		t.Errorf("expected [c], got %v", got)
+	}
+}
+
+func TestParam_GetReturnsCopies(t *testing.T) {
+	type refConfig struct {
+		Price    DecimalParam `cfg:"price"`
+		Addr     IPParam      `cfg:"addr"`
+		Net      IPNetParam   `cfg:"net"`
+		Upstream URLParam     `cfg:"upstream"`
+	}
+
+	cfg := refConfig{
+		Price:    Decimal().Default(big.NewRat(5, 2)).Build(),
+		Addr:     IP().Build(),
+		Net:      IPNet().Build(),
+		Upstream: URL().Build(),
+	}
+	l := NewLoader(Options{Args: []string{
+		"--addr=10.0.0.1", "--net=10.0.0.0/8", "--upstream=https://api.example.com",
+	}})
+	l.Register("", &cfg)
+	if err := l.Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.Price.Get().SetInt64(0)
+	cfg.Addr.Get()[15] = 9
+	cfg.Net.Get().Mask[0] = 0
+	cfg.Upstream.Get().Host = "evil.example.com"
+	if got := cfg.Price.Get(); got.Cmp(big.NewRat(5, 2)) != 0 {
+		t.Errorf("expected 2.5, got %v", got)
+	}
+	if got := cfg.Addr.Get().String(); got != "10.0.0.1" {
+		t.Errorf("expected 10.0.0.1, got %s", got)
+	}
+	if got := cfg.Net.Get().String(); got != "10.0.0.0/8" {
+		t.Errorf("expected 10.0.0.0/8, got %s", got)
+	}
+	if got := cfg.Upstream.Get().Host; got != "api.example.com" {
+		t.Errorf("expected api.example.com, got %s", got)
+	}
+
+	values, err := l.Preview()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	price, ok := values["price"].(*big.Rat)
+	if !ok {
+		t.Fatalf("expected *big.Rat, got %T", values["price"])
+	}
+	price.SetInt64(0)
+	if got := cfg.Price.Get(); got.Cmp(big.NewRat(5, 2)) != 0 {
+		t.Errorf("expected preview not to share the default, got %v", got)
+	}
+}
+
+func TestParam_GetOr(t *testing.T) {
+	type config struct {
+		Timeout IntParam        `cfg:"timeout"`
+		Retries IntParam        `cfg:"retries"`
+		Workers IntParam        `cfg:"workers"`
+		Tags    StringListParam `cfg:"tags"`
+		Hosts   StringListParam `cfg:"hosts"`
+	}
+	cfg := config{
+		Timeout: Int().Build(),
+		Retries: Int().Default(3).Build(),
+		Workers: Int().Build(),
+		Tags:    StringList().Build(),
+		Hosts:   StringList().Build(),
+	}
+	if err := Load(&cfg, Options{Args: []string{"--timeout=0", "--hosts=a"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := cfg.Timeout.GetOr(30); got != 0 {
+		t.Errorf("expected explicitly set zero, got %d", got)
+	}
+	if got := cfg.Retries.GetOr(5); got != 3 {
+		t.Errorf("expected default, got %d", got)
+	}
+	if got := cfg.Workers.GetOr(4); got != 4 {
+		t.Errorf("expected fallback, got %d", got)
+	}
+	if got := cfg.Tags.GetOr([]string{"x"}); !slices.Equal(got, []string{"x"}) {
+		t.Errorf("expected fallback list, got %v", got)
+	}
+	hosts := cfg.Hosts.GetOr(nil)
+	hosts[0] = "z"
+	if got := cfg.Hosts.Get(); got[0] != "a" {
+		t.Errorf("expected GetOr to return a copy, got %v", got)
+	}
+}
+
+func TestParam_Set(t *testing.T) {
+	type config struct {
+		Port IntParam        `cfg:"port"`
+		Tags StringListParam `cfg:"tags"`
+	}
+	var bound int
+	cfg := config{
+		Port: Int().Default(8080).Validate(Range(1, 65535)).BindTo(&bound).Build(),
+		Tags: StringList().Build(),
+	}
+	if err := Load(&cfg, Options{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := cfg.Port.Set(9090); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port.Get() != 9090 || !cfg.Port.IsSet() || bound != 9090 {
+		t.Errorf("expected 9090, set and bound, got %d, %v, %d",
+			cfg.Port.Get(), cfg.Port.IsSet(), bound)
+	}
+	if cfg.Port.origin() != "Set" {
+		t.Errorf("expected origin Set, got %q", cfg.Port.origin())
+	}
+	if cfg.Port.Source() != SourceSet {
+		t.Errorf("expected source set, got %v", cfg.Port.Source())
+	}
+
+	err := cfg.Port.Set(70000)
+	var valErr *ValidationError
+	if !errors.As(err, &valErr) || valErr.Key != "port" {
+		t.Fatalf("expected ValidationError for port, got %v", err)
+	}
+	if cfg.Port.Get() != 9090 {
+		t.Errorf("expected value unchanged after failed Set, got %d", cfg.Port.Get())
+	}
+
+	tags := []string{"a", "b"}
+	if err := cfg.Tags.Set(tags); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tags[0] = "z"
+	if got := cfg.Tags.Get(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("expected Set to store a copy, got %v", got)
+	}
+}
+
+func TestListParam_GetReturnsCopy(t *testing.T) {
+	type listConfig struct {
+		Tags   StringListParam       `cfg:"tags"`
+		Routes NestedStringListParam `cfg:"routes"`
+	}
+	cfg := listConfig{
+		Tags:   StringList().Build(),
+		Routes: NestedStringList().Build(),
+	}
+	if err := Load(&cfg, Options{Args: []string{"--tags=c,a,b", "--routes=a:1"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tags := cfg.Tags.Get()
+	slices.Sort(tags)
+	if got := cfg.Tags.Get(); got[0] != "c" {
+		t.Errorf("expected configured list to be unchanged, got %v", got)
+	}
+
+	routes := cfg.Routes.Get()
+	routes[0][0] = "z"
+	if got := cfg.Routes.Get(); got[0][0] != "a" {
+		t.Errorf("expected configured list to be unchanged, got %v", got)
+	}
+
+	unset := StringList().Build()
+	if unset.Get() != nil {
+		t.Error("expected nil for unset list")
+	}
+}
+
+func BenchmarkStringListParam_Get(b *testing.B) {
+	p := StringList().Default([]string{"alpha", "beta", "gamma", "delta"}).Build()
+	for b.Loop() {
+		_ = p.Get()
+	}
+}
+
+func TestDurationParam_BareNumber(t *testing.T) {
+	tests := []struct {
+		name  string
+		param DurationParam
+		in    string
+		want  time.Duration
+	}{
+		{"suffix", Duration().Build(), "5s", 5 * time.Second},
+		{"minutes", Duration().Build(), "2m", 2 * time.Minute},
+		{"bare seconds", Duration().Build(), "30", 30 * time.Second},
+		{"bare float", Duration().Build(), "1.5", 1500 * time.Millisecond},
+		{"bare with unit", Duration().Unit(time.Millisecond).Build(), "250", 250 * time.Millisecond},
+		{"suffix with unit", Duration().Unit(time.Millisecond).Build(), "2m", 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.param
+			if err := p.setFromString(tt.in, ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Get() != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, p.Get())
+			}
+		})
+	}
+
+	for _, in := range []string{"abc", "NaN", "1e300"} {
+		p := Duration().Build()
+		var parseErr *ParseError
+		if err := p.setFromString(in, ""); !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected ParseError, got %v", in, err)
+		}
+	}
+}
+
+func TestDurationParam_YAMLNumber(t *testing.T) {
+	type Config struct {
+		Timeout DurationParam `cfg:"timeout"`
+		Poll    DurationParam `cfg:"poll"`
+		Delay   DurationParam `cfg:"delay"`
+	}
+
+	cfg := Config{
+		Timeout: Duration().Build(),
+		Poll:    Duration().Unit(time.Millisecond).Build(),
+		Delay:   Duration().Build(),
+	}
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	content := "timeout: 5000000000\npoll: 250\ndelay: \"1.5\"\n"
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(&cfg, Options{ConfigPaths: []string{configFile}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.Timeout.Get(); got != 5*time.Second {
+		t.Errorf("expected YAML number in nanoseconds, got %v", got)
+	}
+	if got := cfg.Poll.Get(); got != 250 {
+		t.Errorf("expected Unit not to apply to YAML numbers, got %v", got)
+	}
+	if got := cfg.Delay.Get(); got != 1500*time.Millisecond {
+		t.Errorf("expected quoted bare number in seconds, got %v", got)
+	}
+}
+
+func TestByteSizeParam(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"512", 512},
+		{"10MB", 10_000_000},
+		{"10mb", 10_000_000},
+		{"1.5 GiB", 3 << 29},
+		{"4KiB", 4096},
+		{"2KB", 2000},
+		{"7B", 7},
+		{"4.1MB", 4_100_000},
+		{"0.3KB", 300},
+	}
+	for _, tt := range tests {
+		p := ByteSize().Build()
+		if err := p.setFromString(tt.in, ""); err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.in, err)
+			continue
+		}
+		if p.Get() != tt.want {
+			t.Errorf("%q: expected %d, got %d", tt.in, tt.want, p.Get())
+		}
+	}
+
+	for _, in := range []string{"abc", "-5MB", "0.5B", "10XB", "MB", "1/2KB", "NaN"} {
+		p := ByteSize().Build()
+		var parseErr *ParseError
+		if err := p.setFromString(in, ""); !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected ParseError, got %v", in, err)
+		}
+	}
+
+	p := ByteSize().Build()
+	if err := p.setFromAny(1048576, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := p.stringValue(); got != "1MiB" {
+		t.Errorf("expected 1MiB, got %s", got)
+	}
+	if err := p.setFromAny(1.5, ""); err == nil {
+		t.Error("expected error for fractional bytes")
+	}
+	if err := p.setFromAny(-1, ""); err == nil {
+		t.Error("expected error for negative bytes")
+	}
+}
@@ -0,0 +1,113 @@
+package confetto
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Usage returns help text for all configuration parameters in the provided
+// struct, in declaration order, for printing on --help. Each parameter is
+// shown with its flag, type and whether it is required, followed by an
+// indented line with its description and default. Defaults of secret
+// parameters are masked with "****".
+func Usage(cfg any) string {
+	return usageParams(collectParams(cfg, ""))
+}
+
+// Usage is like the package-level Usage but covers all registered configs.
+func (l *Loader) Usage() string {
+	return usageParams(l.collectAllParams())
+}
+
+func usageParams(params []Param) string {
+	var b strings.Builder
+	_ = writeUsage(&b, params) // strings.Builder never fails
+	return b.String()
+}
+
+// writeUsage writes the usage text of params to w, one entry per parameter,
+// with its short flags first and its unit label, if any, after the type:
+//
+//	-H, --db.host string (required)
+//	      Database host (default "localhost")
+//	--timeout int (seconds)
+//	      (default 30)
+func writeUsage(w io.Writer, params []Param) error {
+	for _, p := range params {
+		line := "  "
+		for _, name := range p.shortFlags() {
+			line += "-" + name + ", "
+		}
+		line += "--" + p.key() + " " + typeName(p)
+		if u := p.unit(); u != "" {
+			line += " (" + u + ")"
+		}
+		if p.isRequired() {
+			line += " (required)"
+		}
+		line += "\n"
+
+		var details []string
+		if d := p.description(); d != "" {
+			details = append(details, d)
+		}
+		if def, ok := defaultString(p); ok {
+			details = append(details, "(default "+def+")")
+		}
+		if len(details) > 0 {
+			line += "        " + strings.Join(details, " ") + "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeName returns the Go type of the value of p, with time.Duration,
+// time.Time, *big.Rat, *net.IPNet and net.IP shortened to duration, time,
+// decimal, cidr and ip.
+func typeName(p Param) string {
+	if _, ok := p.(*ByteSizeParam); ok {
+		return "size"
+	}
+	name := reflect.TypeOf(p.getAny()).String()
+	name = strings.ReplaceAll(name, "time.Duration", "duration")
+	name = strings.ReplaceAll(name, "time.Time", "time")
+	name = strings.ReplaceAll(name, "*net.IPNet", "cidr")
+	name = strings.ReplaceAll(name, "net.IP", "ip")
+	return strings.ReplaceAll(name, "*big.Rat", "decimal")
+}
+
+// defaultString formats the declared default of p, quoting strings, and
+// reports false if there is none. Inherited values are not defaults.
+func defaultString(p Param) (string, bool) {
+	c := cloneParam(p)
+	c.useDefault()
+	switch {
+	case !c.hasDefault():
+		return "", false
+	case p.isSecret():
+		return maskedValue, true
+	}
+	if s, ok := c.getAny().(string); ok {
+		return strconv.Quote(s), true
+	}
+	return c.stringValue(), true
+}
+
+// helpRequested reports whether args contain -h or --help before the "--"
+// terminator.
+func helpRequested(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case "--":
+			return false
+		case "-h", "--help":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package confetto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsage(t *testing.T) {
+	type DB struct {
+		Host     StringParam `cfg:"host"`
+		Password StringParam `cfg:"password"`
+	}
+	type Config struct {
+		Port    IntParam          `cfg:"port"`
+		DB      DB                `cfg:"db"`
+		Timeout DurationParam     `cfg:"timeout"`
+		Tags    StringListParam   `cfg:"tags"`
+		Delays  DurationListParam `cfg:"delays"`
+		Name    StringParam       `cfg:"name"`
+	}
+
+	cfg := Config{
+		Port: Int().Default(8080).Short("p").Desc("Port to listen on").Build(),
+		DB: DB{
+			Host:     String().Required().Desc("Database host").Build(),
+			Password: String().Default("s3cret").Secret().Desc("Database password").Build(),
+		},
+		Timeout: Duration().Default(5 * time.Second).Build(),
+		Tags:    StringList().Build(),
+		Delays:  DurationList().Desc("Retry delays").Build(),
+		Name:    String().Default("my app").Build(),
+	}
+
+	expected := `  -p, --port int
+        Port to listen on (default 8080)
+  --db.host string (required)
+        Database host
+  --db.password string
+        Database password (default ****)
+  --timeout duration
+        (default 5s)
+  --tags []string
+  --delays []duration
+        Retry delays
+  --name string
+        (default "my app")
+`
+	if got := Usage(&cfg); got != expected {
+		t.Errorf("Usage() =\n%s\nwant:\n%s", got, expected)
+	}
+
+	l := NewLoader(Options{Args: []string{"--port=9090"}})
+	l.Register("", &cfg)
+	_ = l.Load()
+	if got := l.Usage(); got != expected {
+		t.Errorf("expected defaults regardless of loaded values, got:\n%s", got)
+	}
+}
+
+func TestUsage_Unit(t *testing.T) {
+	type Config struct {
+		Timeout IntParam `cfg:"timeout"`
+		Limit   IntParam `cfg:"limit"`
+	}
+
+	cfg := Config{
+		Timeout: Int().Default(30).Unit("seconds").Build(),
+		Limit:   Int().Required().Unit("requests/s").Build(),
+	}
+
+	expected := `  --timeout int (seconds)
+        (default 30)
+  --limit int (requests/s) (required)
+`
+	if got := Usage(&cfg); got != expected {
+		t.Errorf("Usage() =\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestLoad_AutoHelp(t *testing.T) {
+	type Config struct {
+		Host StringParam `cfg:"host"`
+	}
+	newCfg := func() Config {
+		return Config{Host: String().Required().Desc("Server host").Build()}
+	}
+
+	for _, arg := range []string{"-h", "--help"} {
+		cfg := newCfg()
+		var b strings.Builder
+		err := Load(&cfg, Options{Args: []string{arg}, AutoHelp: true, HelpWriter: &b})
+		if !errors.Is(err, ErrHelpRequested) {
+			t.Fatalf("%s: expected ErrHelpRequested before required errors, got %v", arg, err)
+		}
+		if b.String() != Usage(&cfg) {
+			t.Errorf("%s: expected usage text, got:\n%s", arg, b.String())
+		}
+	}
+
+	cfg := newCfg()
+	err := Load(&cfg, Options{Args: []string{"--host=x", "--", "--help"}, AutoHelp: true})
+	if err != nil {
+		t.Errorf("expected --help after the terminator to be ignored, got %v", err)
+	}
+
+	cfg = newCfg()
+	err = Load(&cfg, Options{Args: []string{"--host=x", "--help"}})
+	if err != nil {
+		t.Errorf("expected --help to be ignored without AutoHelp, got %v", err)
+	}
+}
@@ -1,9 +1,21 @@
 package confetto
 
 import (
+	"cmp"
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"runtime"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
@@ -20,6 +32,35 @@ func Range(lo, hi int) func(int) error {
 	}
 }
 
+// Stepped returns a validator that checks if an int is within [lo, hi] and
+// on the grid lo, lo+step, lo+2*step, and so on. The error for an off-grid
+// value names the nearest valid values. Stepped panics if step is not
+// positive.
+func Stepped(lo, hi, step int) func(int) error {
+	if step <= 0 {
+		panic(fmt.Sprintf("confetto: Stepped step must be positive, got %d", step))
+	}
+	return func(v int) error {
+		if v < lo || v > hi {
+			return fmt.Errorf("%w: value %d is not in range [%d, %d]", ErrValidation, v, lo, hi)
+		}
+		if (v-lo)%step == 0 {
+			return nil
+		}
+		below := lo + (v-lo)/step*step
+		if above := below + step; above <= hi {
+			return fmt.Errorf(
+				"%w: value %d is not a multiple of %d from %d (nearest valid: %d or %d)",
+				ErrValidation, v, step, lo, below, above,
+			)
+		}
+		return fmt.Errorf(
+			"%w: value %d is not a multiple of %d from %d (nearest valid: %d)",
+			ErrValidation, v, step, lo, below,
+		)
+	}
+}
+
 // RangeFloat returns a validator that checks if a float64 is within [min, max].
 func RangeFloat(lo, hi float64) func(float64) error {
 	return func(v float64) error {
@@ -30,6 +71,17 @@ func RangeFloat(lo, hi float64) func(float64) error {
 	}
 }
 
+// Probability returns a validator that checks if a float64 is a fraction
+// within [0, 1], such as a sampling rate.
+func Probability() func(float64) error {
+	return func(v float64) error {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("%w: value %g is not a probability in [0, 1]", ErrValidation, v)
+		}
+		return nil
+	}
+}
+
 // RangeDuration returns a validator that checks if a duration is within [min, max].
 func RangeDuration(lo, hi time.Duration) func(time.Duration) error {
 	return func(v time.Duration) error {
@@ -40,6 +92,18 @@ func RangeDuration(lo, hi time.Duration) func(time.Duration) error {
 	}
 }
 
+// RangeBytes returns a validator that checks if a byte size is within
+// [lo, hi], reporting sizes in human-readable form.
+func RangeBytes(lo, hi int64) func(int64) error {
+	return func(v int64) error {
+		if v < lo || v > hi {
+			return fmt.Errorf("%w: value %s is not in range [%s, %s]", ErrValidation,
+				formatByteSize(v), formatByteSize(lo), formatByteSize(hi))
+		}
+		return nil
+	}
+}
+
 // OneOf returns a validator that checks if a value is one of the allowed values.
 func OneOf[T comparable](allowed ...T) func(T) error {
 	return func(v T) error {
@@ -50,6 +114,73 @@ func OneOf[T comparable](allowed ...T) func(T) error {
 	}
 }
 
+// OneOfFold is like OneOf for strings but compares case-insensitively, so
+// OneOfFold("info", "warn") accepts "INFO" and "Info". The value is kept as
+// written; use StringBuilder.NormalizeOneOf to also rewrite it to the
+// allowed spelling.
+func OneOfFold(allowed ...string) func(string) error {
+	return func(v string) error {
+		for _, a := range allowed {
+			if strings.EqualFold(v, a) {
+				return nil
+			}
+		}
+		return fmt.Errorf(
+			"%w: value %q is not one of %q (case-insensitive)", ErrValidation, v, allowed,
+		)
+	}
+}
+
+// NoneOf returns a validator that checks if a value is not one of the
+// forbidden values. It is the inverse of OneOf.
+func NoneOf[T comparable](forbidden ...T) func(T) error {
+	return func(v T) error {
+		if slices.Contains(forbidden, v) {
+			return fmt.Errorf("%w: value %v is forbidden", ErrValidation, v)
+		}
+		return nil
+	}
+}
+
+// OneOfFile returns a validator that checks if a string is one of the values
+// listed in a file, one per line. Blank lines are ignored. The file is read
+// on first use and read again whenever its modification time changes, so the
+// allowlist can be updated without rebuilding the binary.
+func OneOfFile(path string) func(string) error {
+	var (
+		mu      sync.Mutex
+		modTime time.Time
+		allowed map[string]bool
+	)
+	return func(v string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("cannot read allowlist: %w", err)
+		}
+		if allowed == nil || !info.ModTime().Equal(modTime) {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("cannot read allowlist: %w", err)
+			}
+			allowed = make(map[string]bool)
+			for line := range strings.Lines(string(content)) {
+				if line = strings.TrimSpace(line); line != "" {
+					allowed[line] = true
+				}
+			}
+			modTime = info.ModTime()
+		}
+
+		if !allowed[v] {
+			return fmt.Errorf("%w: value %q is not listed in %s", ErrValidation, v, path)
+		}
+		return nil
+	}
+}
+
 // MinLen returns a validator that checks if a string has at least n characters.
 func MinLen(n int) func(string) error {
 	return func(v string) error {
@@ -84,6 +215,90 @@ func NotEmpty() func(string) error {
 	}
 }
 
+// MatchAny returns a validator that checks if a string matches at least one
+// of the given regular expressions, e.g. a UUID or a numeric ID. Patterns
+// are compiled once and MatchAny panics if one is invalid. Patterns are not
+// anchored implicitly, so use ^ and $ to match the whole value.
+func MatchAny(patterns ...string) func(string) error {
+	res := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		res[i] = regexp.MustCompile(p)
+	}
+	return func(v string) error {
+		for _, re := range res {
+			if re.MatchString(v) {
+				return nil
+			}
+		}
+		return fmt.Errorf(
+			"%w: value %q does not match any of %q", ErrValidation, v, patterns,
+		)
+	}
+}
+
+// Matches returns a validator that checks if a string matches the regular
+// expression pattern, e.g. a Kubernetes label value. The pattern is
+// compiled once and Matches panics if it is invalid. It is not anchored
+// implicitly, so "[a-z]+" accepts any value containing a lowercase letter;
+// use ^ and $ to match the whole value.
+func Matches(pattern string) func(string) error {
+	re := regexp.MustCompile(pattern)
+	return func(v string) error {
+		if !re.MatchString(v) {
+			return fmt.Errorf("%w: value %q does not match %q", ErrValidation, v, pattern)
+		}
+		return nil
+	}
+}
+
+// NotMatches is the opposite of Matches: it rejects strings that match the
+// regular expression pattern, e.g. values containing whitespace.
+func NotMatches(pattern string) func(string) error {
+	re := regexp.MustCompile(pattern)
+	return func(v string) error {
+		if re.MatchString(v) {
+			return fmt.Errorf("%w: value %q must not match %q", ErrValidation, v, pattern)
+		}
+		return nil
+	}
+}
+
+// MatchURLScheme returns a validator that checks if a string parses as a URL,
+// such as a database DSN, with one of the given schemes (compared
+// case-insensitively). Credentials and query parameters are allowed; only
+// the structure is checked, not connectivity. The error message does not
+// repeat the value, but the ValidationError reported by Load still holds it
+// in Value and prints it; mark a DSN with credentials Secret to mask it.
+func MatchURLScheme(schemes ...string) func(string) error {
+	return func(v string) error {
+		u, err := url.Parse(v)
+		if err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			return fmt.Errorf("%w: not a valid URL: %v", ErrValidation, err)
+		}
+		return checkScheme(u, schemes)
+	}
+}
+
+// checkScheme checks that u has one of the given schemes, compared
+// case-insensitively.
+func checkScheme(u *url.URL, schemes []string) error {
+	if u.Scheme == "" {
+		return fmt.Errorf("%w: URL has no scheme, expected one of %v", ErrValidation, schemes)
+	}
+	for _, s := range schemes {
+		if strings.EqualFold(u.Scheme, s) {
+			return nil
+		}
+	}
+	return fmt.Errorf(
+		"%w: URL scheme %q is not one of %v", ErrValidation, u.Scheme, schemes,
+	)
+}
+
 // MinItems returns a validator that checks if a slice has at least n items.
 func MinItems[T any](n int) func([]T) error {
 	return func(v []T) error {
@@ -104,6 +319,135 @@ func MaxItems[T any](n int) func([]T) error {
 	}
 }
 
+// AtIndex returns a validator that applies v to the item at index i of a
+// list, e.g. a hostname followed by ports. Lists with no item at i pass.
+func AtIndex[T any](i int, v func(T) error) func([]T) error {
+	return func(items []T) error {
+		if i < 0 || i >= len(items) {
+			return nil
+		}
+		if err := v(items[i]); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+		return nil
+	}
+}
+
+// All returns a validator that passes if every one of validators passes,
+// checking them in order and returning the first failure. It is the same as
+// chaining Validate calls, for use inside Any or AtIndex.
+func All[T any](validators ...func(T) error) func(T) error {
+	return func(v T) error {
+		for _, fn := range validators {
+			if err := fn(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Any returns a validator that passes if at least one of validators passes,
+// e.g. Any(MaxLen(0), MatchURLScheme("https")) for "either empty or an
+// HTTPS URL". Otherwise its error wraps ErrValidation and every failure,
+// and its message lists them all. Any with no validators accepts every
+// value.
+func Any[T any](validators ...func(T) error) func(T) error {
+	return func(v T) error {
+		if len(validators) == 0 {
+			return nil
+		}
+		errs := make([]error, 0, len(validators))
+		for _, fn := range validators {
+			err := fn(v)
+			if err == nil {
+				return nil
+			}
+			errs = append(errs, err)
+		}
+		return &anyError{errs: errs}
+	}
+}
+
+// anyError reports that none of the validators given to Any passed.
+type anyError struct {
+	errs []error
+}
+
+func (e *anyError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = strings.TrimPrefix(err.Error(), ErrValidation.Error()+": ")
+	}
+	return ErrValidation.Error() + ": no alternative passed: " + strings.Join(msgs, "; ")
+}
+
+func (e *anyError) Unwrap() []error {
+	return append([]error{ErrValidation}, e.errs...)
+}
+
+// Sorted returns a validator that checks if a list is in non-decreasing
+// order, reporting the first item that is smaller than the one before it.
+func Sorted[T cmp.Ordered]() func([]T) error {
+	return ordered[T](func(c int) bool { return c <= 0 }, "non-decreasing")
+}
+
+// Ascending returns a validator that checks if a list is in strictly
+// increasing order, so equal neighbours are rejected too.
+func Ascending[T cmp.Ordered]() func([]T) error {
+	return ordered[T](func(c int) bool { return c < 0 }, "ascending")
+}
+
+// Descending returns a validator that checks if a list is in strictly
+// decreasing order.
+func Descending[T cmp.Ordered]() func([]T) error {
+	return ordered[T](func(c int) bool { return c > 0 }, "descending")
+}
+
+// ordered checks that every pair of neighbours compares as accepted by ok.
+func ordered[T cmp.Ordered](ok func(c int) bool, order string) func([]T) error {
+	return func(items []T) error {
+		for i := 1; i < len(items); i++ {
+			if !ok(cmp.Compare(items[i-1], items[i])) {
+				return fmt.Errorf(
+					"%w: item %d (%v) after %v breaks %s order",
+					ErrValidation, i, items[i], items[i-1], order,
+				)
+			}
+		}
+		return nil
+	}
+}
+
+// NonOverlappingCIDRs returns a validator that checks that every item of a
+// list is an IPv4 or IPv6 CIDR such as "10.0.0.0/8" and that no two items
+// are duplicates or overlap, which in allowlists usually means a mistake.
+func NonOverlappingCIDRs() func([]string) error {
+	return func(items []string) error {
+		prefixes := make([]netip.Prefix, len(items))
+		for i, item := range items {
+			p, err := netip.ParsePrefix(item)
+			if err != nil {
+				return fmt.Errorf("%w: item %d (%s) is not a valid CIDR", ErrValidation, i, item)
+			}
+			prefixes[i] = p.Masked()
+			for j, q := range prefixes[:i] {
+				switch {
+				case q == prefixes[i]:
+					return fmt.Errorf(
+						"%w: item %d (%s) duplicates item %d (%s)", ErrValidation, i, item, j, items[j],
+					)
+				case q.Overlaps(prefixes[i]):
+					return fmt.Errorf(
+						"%w: item %d (%s) overlaps item %d (%s)", ErrValidation, i, item, j, items[j],
+					)
+				}
+			}
+		}
+		return nil
+	}
+}
+
 // Positive returns a validator that checks if an int is positive (> 0).
 func Positive() func(int) error {
 	return func(v int) error {
@@ -123,3 +467,118 @@ func NonNegative() func(int) error {
 		return nil
 	}
 }
+
+// ValidGoTemplate returns a validator that checks if a string parses as a
+// text/template template.
+func ValidGoTemplate() func(string) error {
+	return func(v string) error {
+		if _, err := template.New("").Parse(v); err != nil {
+			return fmt.Errorf("%w: invalid template: %v", ErrValidation, err)
+		}
+		return nil
+	}
+}
+
+// ModeAtMost returns a validator that checks if the file at a path grants no
+// permission bits beyond perm, e.g. ModeAtMost(0o600) rejects a private key
+// readable by group or others. Symlinks are followed.
+func ModeAtMost(perm os.FileMode) func(string) error {
+	return func(v string) error {
+		info, err := os.Stat(v)
+		if err != nil {
+			return fmt.Errorf("%w: cannot stat %q: %v", ErrValidation, v, err)
+		}
+		if mode := info.Mode().Perm(); mode&^perm != 0 {
+			return fmt.Errorf(
+				"%w: file %q has mode %#o, allowed at most %#o", ErrValidation, v, mode, perm,
+			)
+		}
+		return nil
+	}
+}
+
+// PortAvailable returns a validator that checks if a TCP port can be listened
+// on, by briefly opening and closing a listener on all interfaces.
+// It has side effects on the host, so only use it for preflight checks.
+func PortAvailable() func(int) error {
+	return func(v int) error {
+		var lc net.ListenConfig
+		ln, err := lc.Listen(context.Background(), "tcp", ":"+strconv.Itoa(v))
+		if err != nil {
+			return fmt.Errorf("%w: port %d is not available: %v", ErrValidation, v, err)
+		}
+		return ln.Close()
+	}
+}
+
+// SocketWritable returns a validator that checks if a Unix socket can be
+// created at the given path, by briefly listening on it. The socket file is
+// removed again when the listener is closed.
+// It has side effects on the host, so only use it for preflight checks.
+func SocketWritable() func(string) error {
+	return func(v string) error {
+		var lc net.ListenConfig
+		ln, err := lc.Listen(context.Background(), "unix", v)
+		if err != nil {
+			return fmt.Errorf("%w: cannot create socket %q: %v", ErrValidation, v, err)
+		}
+		return ln.Close()
+	}
+}
+
+// MaxFractionOfCPUs returns a validator that checks if an int, such as a
+// worker count, is at most f times the number of CPUs usable by the process.
+// The result depends on the host, so only use it where that is intended.
+func MaxFractionOfCPUs(f float64) func(int) error {
+	return func(v int) error {
+		limit := f * float64(runtime.NumCPU())
+		if float64(v) > limit {
+			return fmt.Errorf(
+				"%w: value %d exceeds %g times the %d available CPUs",
+				ErrValidation, v, f, runtime.NumCPU(),
+			)
+		}
+		return nil
+	}
+}
+
+// MaxFractionOfRAM returns a validator that checks if a size in bytes is at
+// most f times the total memory of the host, as reported by /proc/meminfo.
+// The check is skipped where the total memory cannot be determined.
+// The result depends on the host, so only use it where that is intended.
+func MaxFractionOfRAM(f float64) func(int64) error {
+	return func(v int64) error {
+		total, ok := totalMemory()
+		if !ok {
+			return nil
+		}
+		if float64(v) > f*float64(total) {
+			return fmt.Errorf(
+				"%w: value %d bytes exceeds %g times the %d bytes of memory",
+				ErrValidation, v, f, total,
+			)
+		}
+		return nil
+	}
+}
+
+// totalMemory returns the total memory of the host in bytes, and false if
+// it is unknown.
+func totalMemory() (int64, bool) {
+	content, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0, false
+	}
+	for line := range strings.Lines(string(content)) {
+		rest, ok := strings.CutPrefix(line, "MemTotal:")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(rest), " kB"), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return kb * 1024, true
+	}
+	return 0, false
+}
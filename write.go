@@ -0,0 +1,178 @@
+package confetto
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errNotMapping is returned when the YAML document root is not a mapping.
+var errNotMapping = errors.New("yaml document root is not a mapping")
+
+// UpdateYAML returns the original YAML document with the values of the
+// parameters in cfg written into it. Nodes are edited in place, so comments
+// and the order of keys are preserved, and only values that actually differ
+// are rewritten. Keys missing from the document are added only for
+// parameters that are set. Secret parameters are never written.
+func UpdateYAML(original []byte, cfg any) ([]byte, error) {
+	return updateYAML(original, collectParams(cfg, ""))
+}
+
+// UpdateYAML is like the package-level UpdateYAML but writes the parameters
+// of all registered configs.
+func (l *Loader) UpdateYAML(original []byte) ([]byte, error) {
+	return updateYAML(original, l.collectAllParams())
+}
+
+func updateYAML(original []byte, params []Param) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errNotMapping
+	}
+
+	for _, p := range params {
+		if p.isSecret() || (!p.IsSet() && !p.hasDefault()) {
+			continue
+		}
+		if err := updateNode(root, p); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// updateNode writes the value of p at its key path under root.
+func updateNode(root *yaml.Node, p Param) error {
+	var value yaml.Node
+	if err := value.Encode(exportValue(p)); err != nil {
+		return err
+	}
+
+	parts := strings.Split(p.key(), ".")
+	parent := root
+	for i, part := range parts {
+		node := mappingValue(parent, part)
+		last := i == len(parts)-1
+		switch {
+		case node == nil && !p.IsSet():
+			return nil
+		case node == nil && last:
+			parent.Content = append(parent.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, &value)
+			return nil
+		case node == nil:
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			parent.Content = append(parent.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, node)
+		case last:
+			if !nodeHolds(node, p) {
+				value.HeadComment = node.HeadComment
+				value.LineComment = node.LineComment
+				value.FootComment = node.FootComment
+				*node = value
+			}
+			return nil
+		case node.Kind != yaml.MappingNode:
+			return nil
+		}
+		parent = node
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// nodeHolds reports whether the node already holds the value of p, by
+// parsing it into a copy of p and comparing the formatted values. This keeps
+// equivalent spellings such as "1m" for 1m0s untouched.
+func nodeHolds(node *yaml.Node, p Param) bool {
+	var raw any
+	if err := node.Decode(&raw); err != nil || raw == nil {
+		return false
+	}
+	c := cloneParam(p)
+	var err error
+	if s, ok := raw.(string); ok {
+		err = c.setFromString(s, ",")
+	} else {
+		err = c.setFromAny(raw, ",")
+	}
+	return err == nil && c.stringValue() == p.stringValue()
+}
+
+// readLocker is implemented by parameters guarded by a read-write lock.
+type readLocker interface {
+	rlock()
+	runlock()
+}
+
+// cloneParam returns a shallow copy of a parameter, made under its read
+// lock.
+func cloneParam(p Param) Param {
+	if l, ok := p.(readLocker); ok {
+		l.rlock()
+		defer l.runlock()
+	}
+	v := reflect.ValueOf(p)
+	c := reflect.New(v.Elem().Type())
+	c.Elem().Set(v.Elem())
+	return c.Interface().(Param) //nolint:forcetypeassert // same type as p
+}
+
+// exportValue converts the value of p into a plain value suitable for
+// encoding: basic types and lists of them are kept, durations are written
+// in their string form, and other types use their formatted string value.
+// Percentage floats are written as percentages, the way they are read.
+func exportValue(p Param) any {
+	if fp, ok := p.(*FloatParam); ok && fp.percent {
+		return fp.Get() * 100
+	}
+	switch v := p.getAny().(type) {
+	case string, int, int64, bool, float64, []string, []int, []bool, []float64, [][]string,
+		map[string]string:
+		return v
+	case []time.Duration:
+		out := make([]string, len(v))
+		for i, d := range v {
+			out[i] = d.String()
+		}
+		return out
+	default:
+		return p.stringValue()
+	}
+}
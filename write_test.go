@@ -0,0 +1,131 @@
+package confetto
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpdateYAML(t *testing.T) {
+	type DB struct {
+		Host     StringParam   `cfg:"host"`
+		Port     IntParam      `cfg:"port"`
+		Timeout  DurationParam `cfg:"timeout"`
+		Password StringParam   `cfg:"password"`
+	}
+	type Config struct {
+		DB   DB              `cfg:"db"`
+		Tags StringListParam `cfg:"tags"`
+		Mode StringParam     `cfg:"mode"`
+	}
+
+	original := `# database settings
+db:
+  # the host to connect to
+  host: localhost
+  port: 5432 # default postgres port
+  timeout: 1m
+tags: [a, b]
+`
+	cfg := Config{
+		DB: DB{
+			Host:     String().Build(),
+			Port:     Int().Build(),
+			Timeout:  Duration().Build(),
+			Password: String().Secret().Build(),
+		},
+		Tags: StringList().Build(),
+		Mode: String().Default("fast").Build(),
+	}
+	err := Load(&cfg, Options{
+		ConfigMap: map[string]any{
+			"db":   map[string]any{"host": "localhost", "timeout": "1m", "password": "s3cret"},
+			"tags": []any{"a", "b"},
+		},
+		Args: []string{"--db.port=6543", "--tags=a,b,c"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := UpdateYAML([]byte(original), &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `# database settings
+db:
+  # the host to connect to
+  host: localhost
+  port: 6543 # default postgres port
+  timeout: 1m
+tags:
+  - a
+  - b
+  - c
+`
+	if string(out) != expected {
+		t.Errorf("UpdateYAML() =\n%s\nwant:\n%s", out, expected)
+	}
+	if strings.Contains(string(out), "s3cret") {
+		t.Error("secret value must not be written")
+	}
+	if strings.Contains(string(out), "mode") {
+		t.Error("default-only value missing from the file must not be added")
+	}
+}
+
+func TestUpdateYAML_AddsMissingKeys(t *testing.T) {
+	type Config struct {
+		Server struct {
+			Addr    StringParam   `cfg:"addr"`
+			Timeout DurationParam `cfg:"timeout"`
+		} `cfg:"server"`
+	}
+
+	var cfg Config
+	cfg.Server.Addr = String().Build()
+	cfg.Server.Timeout = Duration().Build()
+	err := Load(&cfg, Options{Args: []string{"--server.addr=:9090", "--server.timeout=90s"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, err := UpdateYAML(nil, &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "server:\n  addr: :9090\n  timeout: 1m30s\n"
+	if string(out) != expected {
+		t.Errorf("UpdateYAML() =\n%s\nwant:\n%s", out, expected)
+	}
+
+}
+
+func TestLoader_UpdateYAML_NotMapping(t *testing.T) {
+	type Config struct {
+		Addr StringParam `cfg:"addr"`
+	}
+	cfg := Config{Addr: String().Default(":8080").Build()}
+	l := NewLoader(Options{})
+	l.Register("server", &cfg)
+	if _, err := l.UpdateYAML([]byte("- not a mapping\n")); err == nil {
+		t.Error("expected error for non-mapping document")
+	}
+}
+
+func TestUpdateYAML_Percentage(t *testing.T) {
+	type Config struct {
+		Rate FloatParam `cfg:"rate"`
+	}
+	cfg := Config{Rate: Float().Percentage().Build()}
+	if err := Load(&cfg, Options{Args: []string{"--rate=25"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := UpdateYAML([]byte("rate: 10 # percent\n"), &cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != "rate: 25 # percent\n" {
+		t.Errorf("expected rate written as a percentage, got:\n%s", out)
+	}
+}